@@ -0,0 +1,125 @@
+package sunerror
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// maxSettingsStackRows 运行时设置允许的最大堆栈行数, 避免每个错误都分配过大的堆栈缓冲
+const maxSettingsStackRows = 1024
+
+// settingsMu 串行化ApplySettings对采样与合规模式的读-改-写
+var settingsMu sync.Mutex
+
+// Settings 可在运行时调整的全局设置, 用于AdminHandler; 指针字段为nil表示不修改
+type Settings struct {
+	DefaultLevel   *SunErrLevel `json:"defaultLevel,omitempty"`   // Config.DefaultLevel
+	StoreStack     *bool        `json:"storeStack,omitempty"`     // Config.StoreStackDefault
+	StackRows      *int         `json:"stackRows,omitempty"`      // Config.DefaultStackRows
+	SampleRate     *float64     `json:"sampleRate,omitempty"`     // SamplingConfig.Rate
+	SampleFirstN   *int         `json:"sampleFirstN,omitempty"`   // SamplingConfig.FirstN
+	ComplianceMode *bool        `json:"complianceMode,omitempty"` // SetComplianceMode
+	EnableDebug    *bool        `json:"enableDebug,omitempty"`    // Config.EnableDebug
+}
+
+// CurrentSettings 返回当前生效的设置, 所有字段都不为nil
+func CurrentSettings() Settings {
+	cfg := DefaultConfig()
+	sampling := GetAsyncSampling()
+	compliance := ComplianceMode()
+	return Settings{
+		DefaultLevel:   &cfg.DefaultLevel,
+		StoreStack:     &cfg.StoreStackDefault,
+		StackRows:      &cfg.DefaultStackRows,
+		SampleRate:     &sampling.Rate,
+		SampleFirstN:   &sampling.FirstN,
+		ComplianceMode: &compliance,
+		EnableDebug:    &cfg.EnableDebug,
+	}
+}
+
+// Validate 检查s中不为nil的字段是否合法
+func (s Settings) Validate() error {
+	if s.DefaultLevel != nil && (*s.DefaultLevel < DebugLevel || *s.DefaultLevel > FatalLevel) {
+		return fmt.Errorf("sunerror: invalid defaultLevel %d", *s.DefaultLevel)
+	}
+	if s.StackRows != nil && (*s.StackRows <= 0 || *s.StackRows > maxSettingsStackRows) {
+		return fmt.Errorf("sunerror: stackRows %d out of range [1, %d]", *s.StackRows, maxSettingsStackRows)
+	}
+	if s.SampleRate != nil && !(*s.SampleRate >= 0 && *s.SampleRate <= 1) {
+		return fmt.Errorf("sunerror: sampleRate %v out of range [0, 1]", *s.SampleRate)
+	}
+	if s.SampleFirstN != nil && *s.SampleFirstN < 0 {
+		return fmt.Errorf("sunerror: negative sampleFirstN %d", *s.SampleFirstN)
+	}
+	return nil
+}
+
+// ApplySettings 修改s中不为nil的设置, 其余保持不变; 可以在运行中随时调用, 只影响之后构造的错误
+// 设置不合法时不做任何修改并返回错误; 并发调用依次生效, 不会互相覆盖
+func ApplySettings(s Settings) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+	if s.DefaultLevel != nil || s.StoreStack != nil || s.StackRows != nil || s.EnableDebug != nil {
+		updateConfig(func(cfg *Config) {
+			if s.DefaultLevel != nil {
+				cfg.DefaultLevel = *s.DefaultLevel
+			}
+			if s.StoreStack != nil {
+				cfg.StoreStackDefault = *s.StoreStack
+			}
+			if s.StackRows != nil {
+				cfg.DefaultStackRows = *s.StackRows
+			}
+			if s.EnableDebug != nil {
+				cfg.EnableDebug = *s.EnableDebug
+			}
+		})
+	}
+	if s.SampleRate != nil || s.SampleFirstN != nil {
+		sampling := GetAsyncSampling()
+		if s.SampleRate != nil {
+			sampling.Rate = *s.SampleRate
+		}
+		if s.SampleFirstN != nil {
+			sampling.FirstN = *s.SampleFirstN
+		}
+		SetAsyncSampling(sampling)
+	}
+	if s.ComplianceMode != nil {
+		SetComplianceMode(*s.ComplianceMode)
+	}
+	return nil
+}
+
+// AdminHandler 查看与修改运行时设置的HTTP接口, 用于故障期间不重新发布即可调整(如临时打开堆栈或降低上报采样)
+// GET返回CurrentSettings的JSON; POST/PUT提交Settings的JSON, 只修改提交的字段, 返回修改后的设置
+// 没有鉴权, 只应挂载在内部管理端口上
+func AdminHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost, http.MethodPut:
+			var s Settings
+			if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+				http.Error(w, "invalid settings: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			if err := ApplySettings(s); err != nil {
+				http.Error(w, "invalid settings: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, POST, PUT")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_ = json.NewEncoder(w).Encode(CurrentSettings())
+	})
+}
@@ -0,0 +1,72 @@
+package sunerror
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func restoreSettings(t *testing.T) {
+	old := DefaultConfig()
+	sampling := GetAsyncSampling()
+	compliance := ComplianceMode()
+	t.Cleanup(func() {
+		Configure(old)
+		SetAsyncSampling(sampling)
+		SetComplianceMode(compliance)
+	})
+}
+
+func TestApplySettingsValidates(t *testing.T) {
+	restoreSettings(t)
+	before := CurrentSettings()
+	rate, rows, level, firstN := 1.5, 0, SunErrLevel(9), -1
+	for _, s := range []Settings{
+		{SampleRate: &rate},
+		{StackRows: &rows},
+		{DefaultLevel: &level},
+		{SampleFirstN: &firstN},
+	} {
+		if err := ApplySettings(s); err == nil {
+			t.Fatalf("ApplySettings(%+v) = nil, want error", s)
+		}
+	}
+	if after := CurrentSettings(); *after.SampleRate != *before.SampleRate || *after.StackRows != *before.StackRows || *after.DefaultLevel != *before.DefaultLevel {
+		t.Fatalf("invalid settings were applied: %+v", after)
+	}
+}
+
+func TestApplySettingsConcurrent(t *testing.T) {
+	restoreSettings(t)
+	debug, rows := true, 20
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_ = ApplySettings(Settings{EnableDebug: &debug})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_ = ApplySettings(Settings{StackRows: &rows})
+		}
+	}()
+	wg.Wait()
+	if cfg := DefaultConfig(); !cfg.EnableDebug || cfg.DefaultStackRows != 20 {
+		t.Fatalf("concurrent ApplySettings lost an update: EnableDebug=%v DefaultStackRows=%d", cfg.EnableDebug, cfg.DefaultStackRows)
+	}
+}
+
+func TestAdminHandlerRejectsInvalidSettings(t *testing.T) {
+	restoreSettings(t)
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"sampleRate": -0.5}`))
+	rec := httptest.NewRecorder()
+	AdminHandler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,462 @@
+package sunerror
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy 异步执行池队列已满时的处理策略
+type OverflowPolicy int8
+
+const (
+	// DropPolicy 丢弃新提交的任务并计数, 默认策略
+	DropPolicy OverflowPolicy = iota
+	// BlockPolicy 阻塞等待队列空闲, 错误风暴时会拖慢调用方
+	// 异步执行器中以收到的ctx再构造的错误不会阻塞, 队列已满时在当前工作协程中同步执行
+	BlockPolicy
+	// CallerRunsPolicy 在调用方协程中同步执行
+	CallerRunsPolicy
+)
+
+// AsyncPoolConfig 异步执行池配置, 零值字段使用默认值
+type AsyncPoolConfig struct {
+	Workers   int            // 工作协程数, 默认为runtime.NumCPU()
+	QueueSize int            // 每个优先级队列的长度, 默认1024
+	Overflow  OverflowPolicy // 队列已满时的处理策略, 默认DropPolicy
+}
+
+// AsyncPoolStats 异步执行池的运行指标, 可定期采集上报
+type AsyncPoolStats struct {
+	Workers        int                    // 工作协程数
+	QueueSize      int                    // 每个优先级队列的长度
+	QueueDepth     int                    // 当前排队的任务数
+	HighDepth      int                    // ErrorLevel及以上的队列中排队的任务数
+	LowDepth       int                    // Info/Warn级别的队列中排队的任务数
+	Executed       uint64                 // 已执行的任务数
+	Dropped        uint64                 // 因队列已满被丢弃的任务数
+	DroppedByLevel map[SunErrLevel]uint64 // 按错误等级统计的丢弃数
+}
+
+// asyncPool 有界的异步执行池, 所有SunError的异步执行器共用, 避免错误风暴时协程数暴涨
+// ErrorLevel及以上与Info/Warn级别分为两个队列, 工作协程优先执行高优先级队列,
+// 队列饱和时低等级的任务先被丢弃, 不会挤占ErrorLevel任务的位置
+type asyncPool struct {
+	workers  int
+	high     chan func()
+	low      chan func()
+	overflow OverflowPolicy
+	wg       sync.WaitGroup
+	executed uint64
+	dropped  uint64
+	inflight int64 // 已提交但未执行完的任务数
+
+	// mu 保护closed与向队列的发送, 只在非阻塞的发送期间持有读锁, close取写锁时不会等待阻塞的提交
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{} // close时关闭, 唤醒BlockPolicy下等待队列空闲的提交
+	space  chan struct{} // 工作协程取出任务后通知, BlockPolicy下等待队列空闲
+
+	idleMu sync.Mutex
+	idle   chan struct{} // 有wait等待时创建, inflight降为0时关闭
+
+	dropMu    sync.Mutex
+	droppedBy map[SunErrLevel]uint64
+}
+
+var (
+	poolMu      sync.RWMutex
+	defaultPool *asyncPool
+	poolClosed  bool
+
+	flusherMu sync.Mutex
+	flushers  []*BatchExecutor
+)
+
+// SetAsyncPool 替换全局异步执行池, 旧执行池中已排队的任务会继续执行完; Close/CloseContext之后调用会重新启用异步执行
+func SetAsyncPool(cfg AsyncPoolConfig) {
+	pool := newAsyncPool(cfg)
+	poolMu.Lock()
+	old := defaultPool
+	defaultPool = pool
+	poolClosed = false
+	poolMu.Unlock()
+	if old != nil {
+		old.close()
+	}
+}
+
+// Flush 等待已提交的异步执行器全部执行完, 并刷新所有BatchExecutor的缓冲区, ctx结束时返回ctx.Err()
+// 适合在进程退出前调用, 避免刚产生的错误来不及上报
+func Flush(ctx context.Context) error {
+	if err := waitPool(ctx); err != nil {
+		return err
+	}
+	flusherMu.Lock()
+	batches := append([]*BatchExecutor(nil), flushers...)
+	flusherMu.Unlock()
+	for _, b := range batches {
+		b.Flush(ctx)
+	}
+	return ctx.Err()
+}
+
+func waitPool(ctx context.Context) error {
+	poolMu.RLock()
+	pool := defaultPool
+	poolMu.RUnlock()
+	if pool == nil {
+		return nil
+	}
+	return pool.wait(ctx)
+}
+
+// closeTimeout Close等待异步工作完成的最长时间
+const closeTimeout = 10 * time.Second
+
+// Close 同CloseContext, 最多等待10s, 避免某个执行器卡住时进程无法退出
+func Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+	defer cancel()
+	return CloseContext(ctx)
+}
+
+// CloseContext 等待异步工作全部完成后停止异步执行池, 之后产生的错误不再执行异步执行器
+// ctx结束时不再等待, 仍然停止执行池并返回ctx.Err(), 尚未执行的任务会在后台继续执行完
+func CloseContext(ctx context.Context) error {
+	flushErr := Flush(ctx)
+	poolMu.Lock()
+	pool := defaultPool
+	defaultPool = nil
+	poolClosed = true
+	poolMu.Unlock()
+	if pool == nil {
+		return flushErr
+	}
+	pool.close()
+	stopped := make(chan struct{})
+	go func() {
+		pool.wg.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return flushErr
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// GetAsyncPoolStats 返回全局异步执行池的运行指标
+func GetAsyncPoolStats() AsyncPoolStats {
+	poolMu.RLock()
+	pool := defaultPool
+	poolMu.RUnlock()
+	if pool == nil {
+		return AsyncPoolStats{}
+	}
+	pool.dropMu.Lock()
+	droppedBy := make(map[SunErrLevel]uint64, len(pool.droppedBy))
+	for level, n := range pool.droppedBy {
+		droppedBy[level] = n
+	}
+	pool.dropMu.Unlock()
+	return AsyncPoolStats{
+		Workers:        pool.workers,
+		QueueSize:      cap(pool.high),
+		QueueDepth:     len(pool.high) + len(pool.low),
+		HighDepth:      len(pool.high),
+		LowDepth:       len(pool.low),
+		Executed:       atomic.LoadUint64(&pool.executed),
+		Dropped:        atomic.LoadUint64(&pool.dropped),
+		DroppedByLevel: droppedBy,
+	}
+}
+
+func newAsyncPool(cfg AsyncPoolConfig) *asyncPool {
+	if cfg.Workers <= 0 {
+		cfg.Workers = runtime.NumCPU()
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1024
+	}
+	p := &asyncPool{
+		workers:   cfg.Workers,
+		high:      make(chan func(), cfg.QueueSize),
+		low:       make(chan func(), cfg.QueueSize),
+		overflow:  cfg.Overflow,
+		done:      make(chan struct{}),
+		space:     make(chan struct{}, 1),
+		droppedBy: make(map[SunErrLevel]uint64),
+	}
+	p.wg.Add(cfg.Workers)
+	for i := 0; i < cfg.Workers; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// work 优先执行高优先级队列, 两个队列都关闭且排空后退出
+func (p *asyncPool) work() {
+	defer p.wg.Done()
+	high, low := p.high, p.low
+	for high != nil || low != nil {
+		select {
+		case task, ok := <-high:
+			if !ok {
+				high = nil
+				continue
+			}
+			p.run(task)
+			continue
+		default:
+		}
+		select {
+		case task, ok := <-high:
+			if !ok {
+				high = nil
+				continue
+			}
+			p.run(task)
+		case task, ok := <-low:
+			if !ok {
+				low = nil
+				continue
+			}
+			p.run(task)
+		}
+	}
+}
+
+func (p *asyncPool) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+	p.closed = true
+	close(p.done)
+	close(p.high)
+	close(p.low)
+}
+
+func (p *asyncPool) run(task func()) {
+	select {
+	case p.space <- struct{}{}:
+	default:
+	}
+	task()
+	atomic.AddUint64(&p.executed, 1)
+	p.finish()
+}
+
+// finish 任务执行完或被丢弃, inflight降为0时唤醒wait
+func (p *asyncPool) finish() {
+	if atomic.AddInt64(&p.inflight, -1) != 0 {
+		return
+	}
+	p.idleMu.Lock()
+	if p.idle != nil {
+		close(p.idle)
+		p.idle = nil
+	}
+	p.idleMu.Unlock()
+}
+
+// submit 按错误等级提交到对应队列, 任务自身负责recover
+// accepted为false表示任务被丢弃或执行池已关闭, closed为true表示执行池已关闭, 调用方可以改为提交到新的执行池
+// 阻塞等待与CallerRunsPolicy下的同步执行都不持有锁, 任务中再构造SunError或SetAsyncPool与Close不会死锁
+// nested表示从工作协程中提交, BlockPolicy下队列已满时同步执行, 避免工作协程都在等待队列空闲而没有协程取出任务
+func (p *asyncPool) submit(level SunErrLevel, task func(), nested bool) (accepted, closed bool) {
+	queue := p.low
+	if level >= ErrorLevel {
+		queue = p.high
+	}
+	atomic.AddInt64(&p.inflight, 1)
+	for {
+		sent, closed := p.trySend(queue, task)
+		switch {
+		case closed:
+			p.finish()
+			return false, true
+		case sent:
+			return true, false
+		case p.overflow == CallerRunsPolicy, p.overflow == BlockPolicy && nested:
+			p.run(task)
+			return true, false
+		case p.overflow != BlockPolicy:
+			p.drop(level)
+			return false, false
+		}
+		select {
+		case <-p.space:
+		case <-p.done:
+		}
+	}
+}
+
+// trySend 持有读锁非阻塞地发送到队列
+func (p *asyncPool) trySend(queue chan func(), task func()) (sent, closed bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return false, true
+	}
+	select {
+	case queue <- task:
+		return true, false
+	default:
+		return false, false
+	}
+}
+
+func (p *asyncPool) drop(level SunErrLevel) {
+	atomic.AddUint64(&p.dropped, 1)
+	p.dropMu.Lock()
+	p.droppedBy[level]++
+	p.dropMu.Unlock()
+	p.finish()
+}
+
+// wait 等待已提交的任务全部执行完, ctx结束时返回ctx.Err()
+func (p *asyncPool) wait(ctx context.Context) error {
+	p.idleMu.Lock()
+	if p.idle == nil {
+		p.idle = make(chan struct{})
+	}
+	idle := p.idle
+	p.idleMu.Unlock()
+	// 登记之前inflight可能已经降为0, 此时不会再有人关闭idle
+	if atomic.LoadInt64(&p.inflight) <= 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-idle:
+		return nil
+	}
+}
+
+// submitAsync 提交到全局异步执行池, 首次使用时以默认配置创建; Close之后提交的任务会被丢弃
+// 只在读取执行池时持有poolMu, 提交期间执行池被SetAsyncPool替换时改为提交到新的执行池
+func submitAsync(ctx context.Context, level SunErrLevel, task func()) bool {
+	nested := inPoolWorker(ctx)
+	for {
+		pool := loadAsyncPool()
+		if pool == nil {
+			return false
+		}
+		if accepted, closed := pool.submit(level, task, nested); !closed {
+			return accepted
+		}
+	}
+}
+
+type poolWorkerKey struct{}
+
+// withPoolWorker 标记ctx在异步执行池的工作协程中使用, 异步执行器与WithGoAsyncPool的fn收到的ctx带有该标记
+func withPoolWorker(ctx context.Context) context.Context {
+	if inPoolWorker(ctx) {
+		return ctx
+	}
+	return context.WithValue(ctx, poolWorkerKey{}, true)
+}
+
+func inPoolWorker(ctx context.Context) bool {
+	nested, _ := ctx.Value(poolWorkerKey{}).(bool)
+	return nested
+}
+
+// loadAsyncPool 返回全局异步执行池, 未创建且未Close时以默认配置创建
+func loadAsyncPool() *asyncPool {
+	poolMu.RLock()
+	pool, closed := defaultPool, poolClosed
+	poolMu.RUnlock()
+	if pool != nil || closed {
+		return pool
+	}
+	poolMu.Lock()
+	defer poolMu.Unlock()
+	if defaultPool == nil && !poolClosed {
+		defaultPool = newAsyncPool(AsyncPoolConfig{})
+	}
+	return defaultPool
+}
+
+// asyncExecutor 内部统一的异步执行器, 返回error表示需要按重试设置重试
+type asyncExecutor func(ctx context.Context, sunError *SunError) error
+
+// runAsync 执行异步执行器, 每个执行器单独recover
+// 默认传给执行器的ctx保留原ctx中的值(如traceID)但不继承取消信号, 请求结束后上报不会被取消
+func (e *SunError) runAsync(ctx context.Context, fns []asyncExecutor) {
+	if !e.asyncCtx {
+		ctx = context.WithoutCancel(ctx)
+	}
+	workerCtx := withPoolWorker(ctx)
+	if e.asyncPar {
+		for _, fn := range fns {
+			fn := fn
+			e.safeGo(ctx, func() {
+				e.callExecutor(workerCtx, fn)
+			})
+		}
+		return
+	}
+	e.safeGo(ctx, func() {
+		for _, fn := range fns {
+			e.callExecutor(workerCtx, fn)
+		}
+	})
+}
+
+// minAsyncBackoff 异步执行器重试前的最短等待时间, 避免backoff为0时连续重试
+const minAsyncBackoff = 10 * time.Millisecond
+
+// callExecutor 按超时与重试设置执行单个执行器, panic不会重试; 等待重试期间ctx结束时不再重试
+func (e *SunError) callExecutor(ctx context.Context, fn asyncExecutor) {
+	backoff := max(e.asyncDelay, minAsyncBackoff)
+	for attempt := 1; ; attempt++ {
+		panicked, err := e.callOnce(ctx, fn)
+		if err == nil || panicked {
+			return
+		}
+		if attempt >= e.asyncTries {
+			e.logExecutorFailure(ctx, "async executor failed after %d attempts, code=%s, errorID=%s: %v", attempt, e.code, e.GetErrorID(), err)
+			return
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			e.logExecutorFailure(ctx, "async executor gave up after %d attempts, code=%s, errorID=%s: %v", attempt, e.code, e.GetErrorID(), ctx.Err())
+			return
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// logExecutorFailure 通过错误的日志引擎(未设置时为Config.LogEngine)打印执行器的失败
+func (e *SunError) logExecutorFailure(ctx context.Context, format string, v ...interface{}) {
+	if log := e.getLogFunc(); log != nil {
+		log(ctx, format, v...)
+	}
+}
+
+func (e *SunError) callOnce(ctx context.Context, fn asyncExecutor) (panicked bool, err error) {
+	execCtx := ctx
+	if e.asyncTTL > 0 {
+		var cancel context.CancelFunc
+		execCtx, cancel = context.WithTimeout(ctx, e.asyncTTL)
+		defer cancel()
+	}
+	panicked = true
+	e.safeCall(ctx, func() {
+		err = fn(execCtx, e)
+		panicked = false
+	})
+	return panicked, err
+}
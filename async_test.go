@@ -0,0 +1,200 @@
+package sunerror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// resetAsyncPool 测试结束后恢复默认的异步执行池
+func resetAsyncPool(t testing.TB) {
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = Flush(ctx)
+		SetAsyncPool(AsyncPoolConfig{})
+	})
+}
+
+func TestAsyncPoolBlockPolicyNestedAndReplace(t *testing.T) {
+	resetAsyncPool(t)
+	cfg := AsyncPoolConfig{Workers: 2, QueueSize: 1, Overflow: BlockPolicy}
+	SetAsyncPool(cfg)
+
+	const outer = 200
+	var nested int64
+	inner := WithAsyncExecutor(func(ctx context.Context, e *SunError) {
+		atomic.AddInt64(&nested, 1)
+	})
+	outerExec := WithAsyncExecutor(func(ctx context.Context, e *SunError) {
+		// 执行器中再构造带异步执行器的错误, 队列已满时不能阻塞工作协程
+		NewSunError(ctx, "ASYNC_INNER", "500", "inner", WithNoLogOption(), WithStackOption(false), inner)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var wg sync.WaitGroup
+		for i := 0; i < outer; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				NewSunError(context.Background(), "ASYNC_OUTER", "500", "outer", WithNoLogOption(), WithStackOption(false), outerExec)
+			}()
+		}
+		for i := 0; i < 10; i++ {
+			SetAsyncPool(cfg)
+			time.Sleep(time.Millisecond)
+		}
+		wg.Wait()
+	}()
+	select {
+	case <-done:
+	case <-time.After(20 * time.Second):
+		t.Fatal("submitters deadlocked with SetAsyncPool under BlockPolicy")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	if err := Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if n := atomic.LoadInt64(&nested); n != outer {
+		t.Fatalf("nested executors ran %d times, want %d", n, outer)
+	}
+}
+
+func TestAsyncPoolFlushWaitsForInflight(t *testing.T) {
+	resetAsyncPool(t)
+	SetAsyncPool(AsyncPoolConfig{Workers: 1, QueueSize: 8})
+
+	var ran int64
+	for i := 0; i < 5; i++ {
+		SafeGo(context.Background(), func(ctx context.Context) {
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt64(&ran, 1)
+		}, WithGoAsyncPool(ErrorLevel))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if n := atomic.LoadInt64(&ran); n != 5 {
+		t.Fatalf("ran = %d after Flush, want 5", n)
+	}
+}
+
+func TestAsyncPoolFlushHonoursContext(t *testing.T) {
+	resetAsyncPool(t)
+	SetAsyncPool(AsyncPoolConfig{Workers: 1})
+
+	release := make(chan struct{})
+	SafeGo(context.Background(), func(ctx context.Context) { <-release }, WithGoAsyncPool(ErrorLevel))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := Flush(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Flush = %v, want DeadlineExceeded", err)
+	}
+	close(release)
+}
+
+// captureConfigLog 将Config.LogEngine替换为记录日志的函数, 测试结束后恢复原配置
+func captureConfigLog(t *testing.T) func() []string {
+	old := DefaultConfig()
+	t.Cleanup(func() { Configure(old) })
+	var mu sync.Mutex
+	var lines []string
+	cfg := old
+	cfg.LogEngine = func(ctx context.Context, format string, v ...interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		lines = append(lines, fmt.Sprintf(format, v...))
+	}
+	Configure(cfg)
+	return func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), lines...)
+	}
+}
+
+func TestAsyncRetryLogsThroughConfigEngine(t *testing.T) {
+	resetAsyncPool(t)
+	logs := captureConfigLog(t)
+
+	var attempts int64
+	start := time.Now()
+	NewSunError(context.Background(), "ASYNC_RETRY", "500", "retry", WithNoLogOption(), WithStackOption(false),
+		WithLogEngine(nil), WithAsyncRetry(3, 0),
+		WithRetryableAsyncExecutor(func(ctx context.Context, e *SunError) error {
+			atomic.AddInt64(&attempts, 1)
+			return errors.New("endpoint unavailable")
+		}))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if n := atomic.LoadInt64(&attempts); n != 3 {
+		t.Fatalf("attempts = %d, want 3", n)
+	}
+	if elapsed := time.Since(start); elapsed < 3*minAsyncBackoff {
+		t.Fatalf("retries finished in %v, want a minimum backoff between attempts", elapsed)
+	}
+	if got := logs(); len(got) != 1 || !strings.Contains(got[0], "failed after 3 attempts") {
+		t.Fatalf("logs = %q", got)
+	}
+}
+
+func TestAsyncRetryStopsOnCancel(t *testing.T) {
+	resetAsyncPool(t)
+	logs := captureConfigLog(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var attempts int64
+	NewSunError(ctx, "ASYNC_CANCEL", "500", "cancel", WithNoLogOption(), WithStackOption(false),
+		WithAsyncParentContext(), WithAsyncRetry(5, time.Hour),
+		WithRetryableAsyncExecutor(func(ctx context.Context, e *SunError) error {
+			atomic.AddInt64(&attempts, 1)
+			cancel()
+			return errors.New("endpoint unavailable")
+		}))
+	flushCtx, flushCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer flushCancel()
+	if err := Flush(flushCtx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if n := atomic.LoadInt64(&attempts); n != 1 {
+		t.Fatalf("attempts = %d, want 1", n)
+	}
+	if got := logs(); len(got) != 1 || !strings.Contains(got[0], "gave up") {
+		t.Fatalf("logs = %q", got)
+	}
+}
+
+func TestCloseContextDoesNotHangOnStuckExecutor(t *testing.T) {
+	resetAsyncPool(t)
+	SetAsyncPool(AsyncPoolConfig{Workers: 1})
+
+	release := make(chan struct{})
+	defer close(release)
+	SafeGo(context.Background(), func(ctx context.Context) { <-release }, WithGoAsyncPool(ErrorLevel))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := CloseContext(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("CloseContext = %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("CloseContext returned after %v", elapsed)
+	}
+	if stats := GetAsyncPoolStats(); stats.Workers != 0 {
+		t.Fatalf("pool still running after CloseContext: %+v", stats)
+	}
+}
@@ -0,0 +1,118 @@
+package sunerror
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BatchExecutor 批量异步执行器, 攒够size个错误或每隔interval将错误批量交给fn, 适合Kafka/ClickHouse等批量写入的下游
+// 通过Executor()得到的函数注册到WithAsyncExecutor或RegisterGlobalHook
+type BatchExecutor struct {
+	fn       func(ctx context.Context, batch []*SunError)
+	size     int
+	interval time.Duration
+
+	mu     sync.Mutex
+	buf    []*SunError
+	stop   chan struct{}
+	closed bool
+}
+
+// NewBatchExecutor 创建批量执行器, size默认100, interval为0时只按数量刷新
+// 创建后会在sunerror.Flush时一并刷新, 不再使用时调用Stop
+func NewBatchExecutor(fn func(ctx context.Context, batch []*SunError), size int, interval time.Duration) *BatchExecutor {
+	if size <= 0 {
+		size = 100
+	}
+	b := &BatchExecutor{
+		fn:       fn,
+		size:     size,
+		interval: interval,
+		buf:      make([]*SunError, 0, size),
+		stop:     make(chan struct{}),
+	}
+	if interval > 0 {
+		go b.loop()
+	}
+	flusherMu.Lock()
+	flushers = append(flushers, b)
+	flusherMu.Unlock()
+	return b
+}
+
+// Executor 返回放入缓冲区的异步执行器, 缓冲区满时在当前异步执行协程中刷新
+func (b *BatchExecutor) Executor() func(ctx context.Context, sunError *SunError) {
+	return func(ctx context.Context, sunError *SunError) {
+		b.mu.Lock()
+		b.buf = append(b.buf, sunError)
+		if len(b.buf) < b.size {
+			b.mu.Unlock()
+			return
+		}
+		batch := b.take()
+		b.mu.Unlock()
+		b.emit(ctx, batch)
+	}
+}
+
+// Flush 立即将缓冲区中的错误交给fn
+func (b *BatchExecutor) Flush(ctx context.Context) {
+	b.mu.Lock()
+	batch := b.take()
+	b.mu.Unlock()
+	b.emit(ctx, batch)
+}
+
+// Stop 刷新缓冲区并停止定时刷新, 之后不再参与sunerror.Flush
+func (b *BatchExecutor) Stop() {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.stop)
+	}
+	b.mu.Unlock()
+
+	flusherMu.Lock()
+	for i, registered := range flushers {
+		if registered == b {
+			flushers = append(flushers[:i:i], flushers[i+1:]...)
+			break
+		}
+	}
+	flusherMu.Unlock()
+	b.Flush(context.Background())
+}
+
+func (b *BatchExecutor) loop() {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+			b.Flush(context.Background())
+		}
+	}
+}
+
+// take 取出缓冲区, 调用方需持有b.mu
+func (b *BatchExecutor) take() []*SunError {
+	if len(b.buf) == 0 {
+		return nil
+	}
+	batch := b.buf
+	b.buf = make([]*SunError, 0, b.size)
+	return batch
+}
+
+// emit 执行fn, panic时使用批次中第一个错误的日志引擎打印堆栈
+func (b *BatchExecutor) emit(ctx context.Context, batch []*SunError) {
+	if len(batch) == 0 {
+		return
+	}
+	batch[0].safeCall(ctx, func() {
+		b.fn(ctx, batch)
+	})
+}
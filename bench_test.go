@@ -0,0 +1,122 @@
+package sunerror
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 性能回归基准, 修改构造、格式化或异步路径前后各运行一次并用benchstat比较, 基线见README:
+//
+//	go test -run '^$' -bench . -benchmem -count 10 . > new.txt
+//	benchstat old.txt new.txt
+//
+// TestAllocBudgets以AllocsPerRun固定各热点路径的分配次数, 超出预算时go test直接失败
+
+func benchError(opts ...SunErrOption) *SunError {
+	return NewSunError(context.Background(), "BENCH_1001", "500", "order not found",
+		append([]SunErrOption{WithNoLogOption(), WithDetailOption("orderID=%d", 1001)}, opts...)...)
+}
+
+func BenchmarkNew(b *testing.B) {
+	ctx := context.Background()
+	b.Run("NoStack", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = NewSunError(ctx, "BENCH_1001", "500", "order not found", WithStackOption(false), WithNoLogOption())
+		}
+	})
+	b.Run("Stack", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = NewSunError(ctx, "BENCH_1001", "500", "order not found", WithNoLogOption())
+		}
+	})
+	b.Run("Light", func(b *testing.B) {
+		d := Define("BENCH_1002", "404", "order not found", WithNoLogOption())
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = d.Light(ctx, "")
+		}
+	})
+}
+
+func BenchmarkError(b *testing.B) {
+	b.Run("First", func(b *testing.B) {
+		errs := make([]*SunError, b.N)
+		for i := range errs {
+			errs[i] = benchError()
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			benchSink = errs[i].Error()
+		}
+	})
+	b.Run("Append", func(b *testing.B) {
+		e := benchError()
+		buf := make([]byte, 0, 4096)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			buf = e.AppendError(buf[:0])
+		}
+	})
+}
+
+func BenchmarkMarshalJSON(b *testing.B) {
+	e := benchError()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := json.Marshal(e); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAsync(b *testing.B) {
+	resetAsyncPool(b)
+	ctx := context.Background()
+	var ran int64
+	exec := WithAsyncExecutor(func(ctx context.Context, e *SunError) { atomic.AddInt64(&ran, 1) })
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = NewSunError(ctx, "BENCH_1001", "500", "order not found", WithStackOption(false), WithNoLogOption(), exec)
+	}
+	flushCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if err := Flush(flushCtx); err != nil {
+		b.Fatal(err)
+	}
+}
+
+func TestAllocBudgets(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation counts differ under -race")
+	}
+	ctx := context.Background()
+	d := Define("BENCH_1002", "404", "order not found", WithNoLogOption())
+	marshal := benchError()
+	tests := []struct {
+		name   string
+		budget float64
+		fn     func()
+	}{
+		{"NewStack", 6, func() {
+			_ = NewSunError(ctx, "BENCH_1001", "500", "order not found", WithNoLogOption())
+		}},
+		{"Light", 2, func() { _ = d.Light(ctx, "") }},
+		{"ErrorFirst", 9, func() { benchSink = benchError(WithStackOption(false)).Error() }},
+		{"MarshalJSON", 7, func() { _, _ = json.Marshal(marshal) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := testing.AllocsPerRun(200, tt.fn); got > tt.budget {
+				t.Fatalf("%s allocates %v times, budget %v", tt.name, got, tt.budget)
+			}
+		})
+	}
+}
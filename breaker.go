@@ -0,0 +1,52 @@
+package sunerror
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// BreakerPolicy 判断一次调用的错误是否计入熔断器的失败统计
+type BreakerPolicy func(err error) bool
+
+var (
+	breakerMu     sync.RWMutex
+	breakerPolicy BreakerPolicy = DefaultBreakerPolicy
+)
+
+// SetBreakerPolicy 替换全局熔断判定策略, 传nil时恢复为DefaultBreakerPolicy
+func SetBreakerPolicy(policy BreakerPolicy) {
+	if policy == nil {
+		policy = DefaultBreakerPolicy
+	}
+	breakerMu.Lock()
+	defer breakerMu.Unlock()
+	breakerPolicy = policy
+}
+
+// IsBreakerFailure 按全局熔断判定策略判断err是否计为失败, 供gobreaker/sentinel-go等熔断器适配使用
+func IsBreakerFailure(err error) bool {
+	breakerMu.RLock()
+	policy := breakerPolicy
+	breakerMu.RUnlock()
+	return policy(err)
+}
+
+// DefaultBreakerPolicy 默认熔断判定策略
+// 1. 调用方主动取消(context.Canceled)不计为失败
+// 2. 参数校验/认证/权限/不存在/冲突等调用方导致的SunError不计为失败
+// 3. 其他SunError(下游/超时/临时故障/内部错误/未分类)与非SunError计为失败
+func DefaultBreakerPolicy(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var sunErr *SunError
+	if !errors.As(err, &sunErr) {
+		return true
+	}
+	switch sunErr.GetKind() {
+	case ValidationKind, UnauthenticatedKind, PermissionDeniedKind, NotFoundKind, ConflictKind:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,148 @@
+package sunerror
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// CatalogEntry 错误目录中的一项, 即一个错误定义对调用方的契约
+type CatalogEntry struct {
+	Code       string `json:"code"`
+	Status     string `json:"status"`
+	Msg        string `json:"msg"`
+	HTTPStatus int    `json:"httpStatus"`
+	Kind       string `json:"kind,omitempty"`
+	Retryable  bool   `json:"retryable,omitempty"`
+	UserMsg    string `json:"userMsg,omitempty"`
+	DocsURL    string `json:"docsURL,omitempty"`
+}
+
+// Catalog 按code排序的错误目录, 以JSON文件保存在仓库中作为上一个版本的契约, 见CompareCatalogs
+type Catalog []CatalogEntry
+
+// ExportCatalog 导出Define登记的全部错误定义, HTTP状态码按HTTPStatus的规则计算
+func ExportCatalog() Catalog {
+	defMu.RLock()
+	c := make(Catalog, 0, len(definitions))
+	for _, d := range definitions {
+		t := &d.tmpl
+		c = append(c, CatalogEntry{
+			Code:       t.code,
+			Status:     t.status,
+			Msg:        t.msg,
+			HTTPStatus: t.HTTPStatus(),
+			Kind:       kindName(t.kind),
+			Retryable:  t.retryable,
+			UserMsg:    t.userMsg,
+			DocsURL:    t.docsURL,
+		})
+	}
+	defMu.RUnlock()
+	sort.Slice(c, func(i, j int) bool { return c[i].Code < c[j].Code })
+	return c
+}
+
+// LoadCatalog 读取Save保存的错误目录
+func LoadCatalog(path string) (Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Catalog
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Save 以缩进的JSON格式保存错误目录, 便于在代码评审中查看差异
+func (c Catalog) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// CompareOptions CompareCatalogs的判定规则
+type CompareOptions struct {
+	MsgBreaking bool // msg的变化视为不兼容, 调用方按msg匹配错误时开启
+}
+
+// CatalogChange 两个版本的错误目录之间的一处变化
+type CatalogChange struct {
+	Code     string
+	Field    string // 变化的字段, 新增或删除错误码时为"code"
+	Old      string // 变化前的值, 新增错误码时为空
+	New      string // 变化后的值, 删除错误码时为空
+	Breaking bool   // 是否破坏调用方的兼容性
+}
+
+func (c CatalogChange) String() string {
+	s := c.Code + ": " + c.Field + " " + strconv.Quote(c.Old) + " -> " + strconv.Quote(c.New)
+	if c.Breaking {
+		s += " (breaking)"
+	}
+	return s
+}
+
+// CompareCatalogs 比较上一个版本(old)与当前版本(cur)的错误目录, 按code顺序返回全部变化
+// 删除错误码、status或HTTP状态码变化视为不兼容; 设置MsgBreaking时msg的变化也视为不兼容
+// 新增错误码与kind、retryable、userMsg、docsURL的变化只做记录
+// 可以在普通的单元测试中调用, 不依赖CI平台即可拦截不兼容的变更:
+//
+//	old, err := sunerror.LoadCatalog("testdata/errors.json")
+//	...
+//	for _, c := range sunerror.CompareCatalogs(old, sunerror.ExportCatalog(), sunerror.CompareOptions{}) {
+//		if c.Breaking {
+//			t.Error(c)
+//		}
+//	}
+func CompareCatalogs(old, cur Catalog, opts CompareOptions) []CatalogChange {
+	curByCode := make(map[string]CatalogEntry, len(cur))
+	for _, e := range cur {
+		curByCode[e.Code] = e
+	}
+	oldByCode := make(map[string]CatalogEntry, len(old))
+	var changes []CatalogChange
+	for _, o := range old {
+		oldByCode[o.Code] = o
+		n, ok := curByCode[o.Code]
+		if !ok {
+			changes = append(changes, CatalogChange{Code: o.Code, Field: "code", Old: o.Code, Breaking: true})
+			continue
+		}
+		add := func(field, ov, nv string, breaking bool) {
+			if ov != nv {
+				changes = append(changes, CatalogChange{Code: o.Code, Field: field, Old: ov, New: nv, Breaking: breaking})
+			}
+		}
+		add("status", o.Status, n.Status, true)
+		add("httpStatus", strconv.Itoa(o.HTTPStatus), strconv.Itoa(n.HTTPStatus), true)
+		add("msg", o.Msg, n.Msg, opts.MsgBreaking)
+		add("kind", o.Kind, n.Kind, false)
+		add("retryable", strconv.FormatBool(o.Retryable), strconv.FormatBool(n.Retryable), false)
+		add("userMsg", o.UserMsg, n.UserMsg, false)
+		add("docsURL", o.DocsURL, n.DocsURL, false)
+	}
+	for _, n := range cur {
+		if _, ok := oldByCode[n.Code]; !ok {
+			changes = append(changes, CatalogChange{Code: n.Code, Field: "code", New: n.Code})
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Code < changes[j].Code })
+	return changes
+}
+
+// BreakingChanges 只保留不兼容的变化
+func BreakingChanges(changes []CatalogChange) []CatalogChange {
+	var out []CatalogChange
+	for _, c := range changes {
+		if c.Breaking {
+			out = append(out, c)
+		}
+	}
+	return out
+}
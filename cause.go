@@ -0,0 +1,158 @@
+package sunerror
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"syscall"
+)
+
+// CauseClassifier 根据被包装的原始错误推断分类与是否可重试, ok为false表示无法识别
+type CauseClassifier func(cause error) (kind SunErrKind, retryable bool, ok bool)
+
+var (
+	classifierMu    sync.RWMutex
+	causeClassifier CauseClassifier = DefaultCauseClassifier
+)
+
+// SetCauseClassifier 替换Wrap使用的原始错误分类器, 传nil时恢复为DefaultCauseClassifier
+func SetCauseClassifier(classifier CauseClassifier) {
+	if classifier == nil {
+		classifier = DefaultCauseClassifier
+	}
+	classifierMu.Lock()
+	defer classifierMu.Unlock()
+	causeClassifier = classifier
+}
+
+// DefaultCauseClassifier 默认的原始错误分类器
+// 1. context.DeadlineExceeded与超时的net.Error为TimeoutKind, 可重试
+// 2. io.ErrUnexpectedEOF、连接被重置/拒绝/中断、net.ErrClosed及其他网络操作错误为TransientKind, 可重试
+// 3. 原始错误是SunError时沿用它的分类与是否可重试
+// 4. context.Canceled等其他错误无法识别
+func DefaultCauseClassifier(cause error) (SunErrKind, bool, bool) {
+	var sunErr *SunError
+	if errors.As(cause, &sunErr) {
+		return sunErr.kind, sunErr.retryable, true
+	}
+	if errors.Is(cause, context.DeadlineExceeded) {
+		return TimeoutKind, true, true
+	}
+	var netErr net.Error
+	if errors.As(cause, &netErr) && netErr.Timeout() {
+		return TimeoutKind, true, true
+	}
+	if errors.Is(cause, io.ErrUnexpectedEOF) || errors.Is(cause, net.ErrClosed) ||
+		errors.Is(cause, syscall.ECONNRESET) || errors.Is(cause, syscall.ECONNREFUSED) ||
+		errors.Is(cause, syscall.ECONNABORTED) || errors.Is(cause, syscall.EPIPE) {
+		return TransientKind, true, true
+	}
+	var opErr *net.OpError
+	if errors.As(cause, &opErr) {
+		return TransientKind, true, true
+	}
+	return UnknownKind, false, false
+}
+
+// WithCauseOption 设置被包装的原始错误, errors.Is/As可以穿过SunError匹配到它, Error()中以cause=输出
+// 只记录原始错误, 不推断分类; 需要自动分类时使用Wrap
+func WithCauseOption(cause error) SunErrOption {
+	return func(e *SunError) {
+		e.cause = cause
+	}
+}
+
+// Unwrap 返回被包装的原始错误, 没有时返回nil
+func (e *SunError) Unwrap() error {
+	return e.cause
+}
+
+// Wrap 包装原始错误, err为nil时返回nil
+// 按SetCauseClassifier设置的分类器推断kind与是否可重试(如超时、连接重置), opts中的WithKindOption/WithRetryableOption优先
+// err是ctx的取消/超时且ctx设置了context.Cause时, 原始错误中同时包含取消的原因
+func Wrap(ctx context.Context, err error, code Code, status, msg string, opts ...SunErrOption) *SunError {
+	if err == nil {
+		return nil
+	}
+	fields := append(causeOptions(ctx, err), WithSkipDepthOption(1))
+	return NewSunError(ctx, code, status, msg, append(fields, opts...)...)
+}
+
+// Wrap 按定义包装原始错误, 与sunerror.Wrap一样推断分类, err为nil时返回nil
+// 推断结果优先于定义的选项, opts中的选项优先于推断结果
+func (d *Definition) Wrap(ctx context.Context, err error, opts ...SunErrOption) *SunError {
+	if err == nil {
+		return nil
+	}
+	fields := append(causeOptions(ctx, err), WithSkipDepthOption(1))
+	return d.New(ctx, append(fields, opts...)...)
+}
+
+// causeOptions 记录原始错误并应用分类器的推断结果
+func causeOptions(ctx context.Context, err error) []SunErrOption {
+	err = withContextCause(ctx, err)
+	classifierMu.RLock()
+	classify := causeClassifier
+	classifierMu.RUnlock()
+	fields := []SunErrOption{WithCauseOption(err)}
+	if kind, retryable, ok := classify(err); ok {
+		fields = append(fields, WithKindOption(kind), WithRetryableOption(retryable))
+	}
+	return fields
+}
+
+// FromContextError使用的错误码与status
+const (
+	ContextCanceledCode Code = "CONTEXT_CANCELED"
+	ContextDeadlineCode Code = "CONTEXT_DEADLINE_EXCEEDED"
+	ContextErrorStatus       = "FAILED"
+)
+
+// FromContextError ctx已取消或超时时返回包装了ctx.Err()的SunError, 否则返回nil
+// 1. 超时为ContextDeadlineCode, 取消为ContextCanceledCode(WarnLevel, 通常是调用方主动取消)
+// 2. 通过context.WithCancelCause等设置了原因时, 原始错误为"context canceled: 原因", errors.Is可以匹配到原因
+func FromContextError(ctx context.Context, opts ...SunErrOption) *SunError {
+	err := ctx.Err()
+	if err == nil {
+		return nil
+	}
+	code, msg := ContextCanceledCode, "context canceled"
+	fields := append(causeOptions(ctx, err), WithSkipDepthOption(1))
+	if errors.Is(err, context.DeadlineExceeded) {
+		code, msg = ContextDeadlineCode, "context deadline exceeded"
+	} else {
+		fields = append(fields, WithLogLevelOption(WarnLevel))
+	}
+	return NewSunError(ctx, code, ContextErrorStatus, msg, append(fields, opts...)...)
+}
+
+// contextCause ctx的取消/超时错误及context.Cause给出的原因
+type contextCause struct {
+	err   error
+	cause error
+}
+
+func (c *contextCause) Error() string {
+	return c.err.Error() + ": " + c.cause.Error()
+}
+
+func (c *contextCause) Unwrap() []error {
+	return []error{c.err, c.cause}
+}
+
+// withContextCause err是ctx的取消/超时且context.Cause给出了不同的原因时, 附加该原因
+func withContextCause(ctx context.Context, err error) error {
+	if ctx == nil || ctx.Err() == nil {
+		return err
+	}
+	if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	cause := context.Cause(ctx)
+	if cause == nil || cause == ctx.Err() || errors.Is(err, cause) {
+		return err
+	}
+	return &contextCause{err: err, cause: cause}
+}
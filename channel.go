@@ -0,0 +1,65 @@
+package sunerror
+
+import (
+	"strconv"
+	"time"
+)
+
+// channelCall WithChannelCallInfo记录的下游调用信息
+type channelCall struct {
+	endpoint string
+	latency  time.Duration
+	attempt  int
+}
+
+// WithChannelCallInfo 记录下游调用的信息: 调用的端点(如服务名/方法或去掉参数的URL)、本次调用的耗时与第几次尝试(从1开始)
+// 与WithChannelRespOption一起使用, 随Error()、logfmt、MetricLabels与序列化结果输出, 分析下游故障时不需要再关联调用日志
+func WithChannelCallInfo(endpoint string, latency time.Duration, attempt int) SunErrOption {
+	return func(e *SunError) {
+		e.channelCall = &channelCall{endpoint: endpoint, latency: latency, attempt: attempt}
+	}
+}
+
+// GetChannelCallInfo 下游调用的端点、耗时与尝试次数, ok为false表示未设置WithChannelCallInfo
+func (e *SunError) GetChannelCallInfo() (endpoint string, latency time.Duration, attempt int, ok bool) {
+	if e.channelCall == nil {
+		return "", 0, 0, false
+	}
+	return e.channelCall.endpoint, e.channelCall.latency, e.channelCall.attempt, true
+}
+
+// MetricLabels 用于metrics打点的低基数标签: code、level、kind, 设置了下游错误码与WithChannelCallInfo时
+// 另有channel_code、channel_endpoint与channel_attempt; 耗时应作为指标的值(如histogram)而不是标签, 见GetChannelCallInfo
+func (e *SunError) MetricLabels() map[string]string {
+	labels := map[string]string{
+		"code":  e.code,
+		"level": e.level.String(),
+		"kind":  e.kind.String(),
+	}
+	if len(e.channelCode) > 0 {
+		labels["channel_code"] = e.channelCode
+	}
+	if c := e.channelCall; c != nil {
+		labels["channel_endpoint"] = c.endpoint
+		labels["channel_attempt"] = strconv.Itoa(c.attempt)
+	}
+	return labels
+}
+
+// channelCallText Error()中下游调用信息的部分, 未设置时为空
+func (e *SunError) channelCallText() string {
+	c := e.channelCall
+	if c == nil {
+		return ""
+	}
+	return ", channelEndpoint=" + errorValue(c.endpoint) +
+		", channelLatency=" + c.latency.String() +
+		", channelAttempt=" + strconv.Itoa(c.attempt)
+}
+
+func equalChannelCall(a, b *channelCall) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,102 @@
+package sunerror
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	httpStatusMu sync.RWMutex
+	httpStatuses = map[Code]int{}
+)
+
+// RegisterHTTPStatus 为指定错误码注册HTTP状态码, 优先于按kind的默认映射
+func RegisterHTTPStatus(code Code, httpStatus int) {
+	httpStatusMu.Lock()
+	defer httpStatusMu.Unlock()
+	httpStatuses[code] = httpStatus
+}
+
+// HTTPStatus 返回SunError对应的HTTP状态码, 供HTTP中间件与适配层决定响应的状态码
+// 优先使用RegisterHTTPStatus注册的映射, 否则使用SetHTTPHeuristic设置的推断函数, 默认为DefaultHTTPHeuristic
+func (e *SunError) HTTPStatus() int {
+	httpStatusMu.RLock()
+	httpStatus, ok := httpStatuses[Code(e.code)]
+	httpStatusMu.RUnlock()
+	if ok {
+		return httpStatus
+	}
+	if h, _ := httpHeuristic.Load().(func(e *SunError) int); h != nil {
+		if httpStatus := h(e); httpStatus > 0 {
+			return httpStatus
+		}
+	}
+	return DefaultHTTPHeuristic(e)
+}
+
+// httpHeuristic SetHTTPHeuristic设置的推断函数, 未设置时为nil
+var httpHeuristic atomic.Value
+
+// SetHTTPHeuristic 设置没有注册映射的错误码的HTTP状态码推断函数, 返回<=0时使用DefaultHTTPHeuristic; 传nil时恢复默认
+// 可以在推断函数中按团队的约定补充规则, 其余情况交给默认规则:
+//
+//	sunerror.SetHTTPHeuristic(func(e *sunerror.SunError) int {
+//		if strings.HasPrefix(e.GetCode(), "RATE_") {
+//			return http.StatusTooManyRequests
+//		}
+//		return 0
+//	})
+func SetHTTPHeuristic(h func(e *SunError) int) {
+	httpHeuristic.Store(h)
+}
+
+// DefaultHTTPHeuristic 按kind推断HTTP状态码, 新增的错误码不注册映射时也不会都成为500
+// 校验失败400, 未认证401, 无权限403, 不存在404, 冲突409, 下游异常502, 临时故障503, 超时504, 内部错误与未分类500
+// 日志等级不参与推断, 业务上预期的拒绝(如余额不足)应设置kind或通过RegisterHTTPStatus注册
+func DefaultHTTPHeuristic(e *SunError) int {
+	switch e.kind {
+	case ValidationKind:
+		return http.StatusBadRequest
+	case UnauthenticatedKind:
+		return http.StatusUnauthorized
+	case PermissionDeniedKind:
+		return http.StatusForbidden
+	case NotFoundKind:
+		return http.StatusNotFound
+	case ConflictKind:
+		return http.StatusConflict
+	case DownstreamKind:
+		return http.StatusBadGateway
+	case TransientKind:
+		return http.StatusServiceUnavailable
+	case TimeoutKind:
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
+
+// IsClientError err是否由调用方导致(对应4xx), 计算可用性SLO时应排除这类错误
+// SunError按HTTPStatus判断; 调用方主动取消(context.Canceled)也视为调用方导致; 其他非SunError为false
+func IsClientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var sunErr *SunError
+	if errors.As(err, &sunErr) {
+		return isClientStatus(sunErr.HTTPStatus())
+	}
+	return errors.Is(err, context.Canceled)
+}
+
+// IsServerError err是否由服务端或下游导致(对应5xx), 与IsClientError互斥
+// 未分类的SunError与非SunError都视为服务端错误
+func IsServerError(err error) bool {
+	return err != nil && !IsClientError(err)
+}
+
+func isClientStatus(httpStatus int) bool {
+	return httpStatus >= http.StatusBadRequest && httpStatus < http.StatusInternalServerError
+}
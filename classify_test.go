@@ -0,0 +1,22 @@
+package sunerror
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestDefaultHTTPHeuristicIgnoresLevel(t *testing.T) {
+	ctx := context.Background()
+	for _, level := range []SunErrLevel{DebugLevel, InfoLevel, WarnLevel, ErrorLevel, FatalLevel} {
+		e := NewSunError(ctx, "HEURISTIC_1", "500", "unclassified", WithNoLogOption(), WithStackOption(false), WithLogLevelOption(level))
+		if got := DefaultHTTPHeuristic(e); got != http.StatusInternalServerError {
+			t.Fatalf("UnknownKind at %v = %d, want 500", level, got)
+		}
+	}
+	e := NewSunError(ctx, "HEURISTIC_2", "400", "bad input", WithNoLogOption(), WithStackOption(false),
+		WithKindOption(ValidationKind), WithLogLevelOption(InfoLevel))
+	if got := DefaultHTTPHeuristic(e); got != http.StatusBadRequest {
+		t.Fatalf("ValidationKind = %d, want 400", got)
+	}
+}
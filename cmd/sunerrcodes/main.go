@@ -0,0 +1,40 @@
+// Command sunerrcodes 从Catalog.Save保存的错误目录生成错误码常量
+//
+//	//go:generate go run github.com/sjmshsh/sunerror/cmd/sunerrcodes -catalog errors.json -pkg codes -o codes_gen.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sjmshsh/sunerror"
+)
+
+func main() {
+	catalog := flag.String("catalog", "errors.json", "catalog file written by sunerror.Catalog.Save")
+	pkg := flag.String("pkg", "codes", "package name of the generated file")
+	out := flag.String("o", "codes_gen.go", "output file, - for stdout")
+	flag.Parse()
+
+	if err := run(*catalog, *pkg, *out); err != nil {
+		fmt.Fprintln(os.Stderr, "sunerrcodes:", err)
+		os.Exit(1)
+	}
+}
+
+func run(catalog, pkg, out string) error {
+	c, err := sunerror.LoadCatalog(catalog)
+	if err != nil {
+		return err
+	}
+	src, err := c.GenerateCodes(pkg)
+	if err != nil {
+		return err
+	}
+	if out == "-" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(out, src, 0o644)
+}
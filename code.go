@@ -0,0 +1,145 @@
+package sunerror
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// maxCodeLen 错误码的最大字节数
+const maxCodeLen = 64
+
+// Code 错误码, 底层为string, 可以直接用==比较
+// 错误码由命名空间与短码组成, 以第一个_、.或-分隔, 如"ORDER_1001"的命名空间为"ORDER"、短码为"1001"
+// 调用方应使用Catalog.GenerateCodes从错误目录生成的常量, 而不是手写字符串, 拼错的错误码在编译时即可发现
+// NewSunError、Define、Wrap与RegisterHTTPStatus等以Code为参数, 字符串常量可以直接传入, string变量需要显式转换
+//
+//	if codes.CodeOrder1001.Is(err) { ... }
+type Code string
+
+// ParseCode 校验并转换错误码, 见Code.Validate
+func ParseCode(s string) (Code, error) {
+	c := Code(s)
+	if err := c.Validate(); err != nil {
+		return "", err
+	}
+	return c, nil
+}
+
+// MustParseCode 同ParseCode, 错误码不合法时panic, 用于包级变量
+func MustParseCode(s string) Code {
+	c, err := ParseCode(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// CodeOf 返回err链中SunError的错误码, err为nil或不是SunError时返回空
+func CodeOf(err error) Code {
+	var sunErr *SunError
+	if err == nil || !errors.As(err, &sunErr) {
+		return ""
+	}
+	return Code(sunErr.code)
+}
+
+func (c Code) String() string {
+	return string(c)
+}
+
+// Namespace 错误码的命名空间, 即第一个分隔符之前的部分, 没有分隔符时为空
+func (c Code) Namespace() string {
+	if i := strings.IndexAny(string(c), "_.-"); i >= 0 {
+		return string(c[:i])
+	}
+	return ""
+}
+
+// Short 去掉命名空间后的短码, 没有分隔符时为整个错误码
+func (c Code) Short() string {
+	if i := strings.IndexAny(string(c), "_.-"); i >= 0 {
+		return string(c[i+1:])
+	}
+	return string(c)
+}
+
+// Is err链中SunError的错误码是否为c
+func (c Code) Is(err error) bool {
+	return len(c) > 0 && CodeOf(err) == c
+}
+
+// Validate 错误码不为空、不超过64字节, 只包含字母、数字与分隔符_.-, 且不以分隔符开头或结尾、不包含连续的分隔符
+func (c Code) Validate() error {
+	if len(c) == 0 {
+		return errors.New("sunerror: empty code")
+	}
+	if len(c) > maxCodeLen {
+		return fmt.Errorf("sunerror: code %q longer than %d bytes", string(c), maxCodeLen)
+	}
+	sep := true
+	for i := 0; i < len(c); i++ {
+		switch ch := c[i]; {
+		case 'A' <= ch && ch <= 'Z', 'a' <= ch && ch <= 'z', '0' <= ch && ch <= '9':
+			sep = false
+		case ch == '_' || ch == '.' || ch == '-':
+			if sep {
+				return fmt.Errorf("sunerror: code %q has an empty segment", string(c))
+			}
+			sep = true
+		default:
+			return fmt.Errorf("sunerror: code %q contains invalid character %q", string(c), rune(ch))
+		}
+	}
+	if sep {
+		return fmt.Errorf("sunerror: code %q ends with a separator", string(c))
+	}
+	return nil
+}
+
+// GenerateCodes 生成声明错误目录中全部错误码常量的Go源文件, 常量名为Code加上驼峰形式的错误码, 如ORDER_1001对应CodeOrder1001
+// 通常通过cmd/sunerrcodes在go generate中调用:
+//
+//	//go:generate go run github.com/sjmshsh/sunerror/cmd/sunerrcodes -catalog errors.json -pkg codes -o codes_gen.go
+func (c Catalog) GenerateCodes(pkg string) ([]byte, error) {
+	if !token.IsIdentifier(pkg) {
+		return nil, fmt.Errorf("sunerror: invalid package name %q", pkg)
+	}
+	var b bytes.Buffer
+	b.WriteString("// Code generated by sunerrcodes. DO NOT EDIT.\n\n")
+	b.WriteString("package " + pkg + "\n\n")
+	b.WriteString("import \"github.com/sjmshsh/sunerror\"\n\n")
+	b.WriteString("const (\n")
+	names := make(map[string]string, len(c))
+	for _, e := range c {
+		if err := Code(e.Code).Validate(); err != nil {
+			return nil, err
+		}
+		name := codeConstName(e.Code)
+		if other, ok := names[name]; ok {
+			return nil, fmt.Errorf("sunerror: codes %q and %q both map to %s", other, e.Code, name)
+		}
+		names[name] = e.Code
+		if len(e.Msg) > 0 {
+			b.WriteString("\t// " + name + " " + strings.ReplaceAll(e.Msg, "\n", " ") + "\n")
+		}
+		b.WriteString("\t" + name + " sunerror.Code = " + strconv.Quote(e.Code) + "\n")
+	}
+	b.WriteString(")\n")
+	return format.Source(b.Bytes())
+}
+
+// codeConstName ORDER_NOT_FOUND -> CodeOrderNotFound
+func codeConstName(code string) string {
+	var b strings.Builder
+	b.WriteString("Code")
+	for _, seg := range strings.FieldsFunc(code, func(r rune) bool { return r == '_' || r == '.' || r == '-' }) {
+		b.WriteString(strings.ToUpper(seg[:1]))
+		b.WriteString(strings.ToLower(seg[1:]))
+	}
+	return b.String()
+}
@@ -0,0 +1,44 @@
+package sunerror
+
+import (
+	"encoding/base64"
+	"encoding/gob"
+	"encoding/json"
+)
+
+// 注册后error/interface{}类型的字段中的*SunError也能被gob编码
+func init() {
+	gob.Register(&SunError{})
+}
+
+// MarshalText 紧凑的文本形式: 不含堆栈的JSON再以URL安全的base64(无填充)编码, 可以放入缓存、cookie与header
+// 实现encoding.TextMarshaler
+func (e *SunError) MarshalText() ([]byte, error) {
+	data, err := json.Marshal(e.ToRecord(false))
+	if err != nil {
+		return nil, err
+	}
+	text := make([]byte, base64.RawURLEncoding.EncodedLen(len(data)))
+	base64.RawURLEncoding.Encode(text, data)
+	return text, nil
+}
+
+// UnmarshalText 从MarshalText的结果还原, 与UnmarshalJSON一样不打印日志也不执行执行器
+func (e *SunError) UnmarshalText(text []byte) error {
+	data := make([]byte, base64.RawURLEncoding.DecodedLen(len(text)))
+	n, err := base64.RawURLEncoding.Decode(data, text)
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalJSON(data[:n])
+}
+
+// GobEncode 实现gob.GobEncoder, 内容与MarshalJSON相同(包括堆栈), 供以gob编码负载的RPC框架与缓存使用
+func (e *SunError) GobEncode() ([]byte, error) {
+	return e.MarshalJSON()
+}
+
+// GobDecode 实现gob.GobDecoder
+func (e *SunError) GobDecode(data []byte) error {
+	return e.UnmarshalJSON(data)
+}
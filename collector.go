@@ -0,0 +1,97 @@
+package sunerror
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Collector 收集请求处理过程中不影响主流程的SunError(如扇出调用中部分失败), 结束时统一返回给调用方
+// 可以在多个协程中并发Add
+type Collector struct {
+	mu   sync.Mutex
+	errs []*SunError
+}
+
+type collectorKey struct{}
+
+// NewCollector 创建错误收集器
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+// ContextWithCollector 返回绑定了收集器的ctx, 请求范围内通过Collect添加错误
+func ContextWithCollector(ctx context.Context, c *Collector) context.Context {
+	return context.WithValue(ctx, collectorKey{}, c)
+}
+
+// CollectorFromContext 返回ctx绑定的收集器, 没有时返回nil
+func CollectorFromContext(ctx context.Context) *Collector {
+	c, _ := ctx.Value(collectorKey{}).(*Collector)
+	return c
+}
+
+// Collect 将err中的SunError添加到ctx绑定的收集器, 返回是否添加成功
+// ctx没有绑定收集器或err不是SunError时返回false, 调用方应按原来的方式处理该错误
+func Collect(ctx context.Context, err error) bool {
+	c := CollectorFromContext(ctx)
+	if c == nil {
+		return false
+	}
+	var sunErr *SunError
+	if !errors.As(err, &sunErr) {
+		return false
+	}
+	c.Add(sunErr)
+	return true
+}
+
+// Add 添加错误, nil会被忽略
+func (c *Collector) Add(e *SunError) {
+	if e == nil {
+		return
+	}
+	c.mu.Lock()
+	c.errs = append(c.errs, e)
+	c.mu.Unlock()
+}
+
+// Len 已收集的错误数
+func (c *Collector) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.errs)
+}
+
+// Errors 按添加顺序返回已收集的错误的副本切片
+func (c *Collector) Errors() []*SunError {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*SunError(nil), c.errs...)
+}
+
+// Warnings 将已收集的错误转换为响应体, 用于响应中的warnings部分, 没有错误时返回nil
+func (c *Collector) Warnings() []ResponseBody {
+	errs := c.Errors()
+	if len(errs) == 0 {
+		return nil
+	}
+	warnings := make([]ResponseBody, len(errs))
+	for i, e := range errs {
+		warnings[i] = e.ToResponseBody()
+	}
+	return warnings
+}
+
+// Err 将已收集的错误合并为一个error(errors.Join), errors.As可以取出其中的SunError, 没有错误时返回nil
+func (c *Collector) Err() error {
+	errs := c.Errors()
+	if len(errs) == 0 {
+		return nil
+	}
+	joined := make([]error, len(errs))
+	for i, e := range errs {
+		joined[i] = e
+	}
+	return errors.Join(joined...)
+}
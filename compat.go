@@ -0,0 +1,100 @@
+package sunerror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// New/Newf产生的错误使用的错误码与status, 迁移完成前可以通过RegisterDecorator(fn, UncodedCode)按msg或原始错误补充错误码
+const (
+	UncodedCode   Code = "UNCODED"
+	UncodedStatus      = "FAILED"
+)
+
+// New 替代errors.New, 构造错误码为UncodedCode、msg为text的SunError, 与NewSunError一样打印日志并执行执行器
+func New(ctx context.Context, text string, opts ...SunErrOption) *SunError {
+	fields := []SunErrOption{WithSkipDepthOption(1)}
+	return NewSunError(ctx, UncodedCode, UncodedStatus, text, append(fields, opts...)...)
+}
+
+// Newf 替代fmt.Errorf, 构造错误码为UncodedCode的SunError
+// format中%w对应的错误作为原始错误(多个%w时以errors.Join合并), errors.Is/As可以穿过它匹配, 并像Wrap一样推断kind与是否可重试
+// msg中%w按%v格式化, 其中的SunError只取msg, 完整内容仍在Error()的cause中
+//
+//	return sunerror.Newf(ctx, "load user %d: %w", id, err)
+func Newf(ctx context.Context, format string, args ...interface{}) *SunError {
+	err := fmt.Errorf(format, args...)
+	var cause error
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		cause = u.Unwrap()
+	case interface{ Unwrap() []error }:
+		cause = errors.Join(u.Unwrap()...)
+	}
+	if cause == nil {
+		return NewSunError(ctx, UncodedCode, UncodedStatus, err.Error(), WithSkipDepthOption(1))
+	}
+	msgArgs := make([]interface{}, len(args))
+	for i, arg := range args {
+		if sunErr, ok := arg.(*SunError); ok {
+			arg = sunErr.GetMsg()
+		}
+		msgArgs[i] = arg
+	}
+	msg := fmt.Sprintf(wrapVerbToV(format), msgArgs...)
+	fields := append(causeOptions(ctx, cause), WithSkipDepthOption(1))
+	return NewSunError(ctx, UncodedCode, UncodedStatus, msg, fields...)
+}
+
+// WithCodeOption 替换错误码与status, 用于装饰器为New/Newf产生的UncodedCode错误补充正式的错误码
+func WithCodeOption(code, status string) SunErrOption {
+	return func(e *SunError) {
+		e.code = code
+		e.status = status
+	}
+}
+
+// Is 同errors.Is, 迁移时可以直接把errors包替换为sunerror
+func Is(err, target error) bool {
+	return errors.Is(err, target)
+}
+
+// As 同errors.As
+func As(err error, target interface{}) bool {
+	return errors.As(err, target)
+}
+
+// Unwrap 同errors.Unwrap
+func Unwrap(err error) error {
+	return errors.Unwrap(err)
+}
+
+// Join 同errors.Join
+func Join(errs ...error) error {
+	return errors.Join(errs...)
+}
+
+// wrapVerbToV 将格式串中的%w替换为%v, 保留%%与其他动词
+func wrapVerbToV(format string) string {
+	if !strings.Contains(format, "%w") {
+		return format
+	}
+	b := []byte(format)
+	for i := 0; i < len(b); i++ {
+		if b[i] != '%' {
+			continue
+		}
+		for i++; i < len(b); i++ {
+			c := b[i]
+			if c == '%' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') {
+				if c == 'w' {
+					b[i] = 'v'
+				}
+				break
+			}
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,64 @@
+package sunerror
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+var complianceMode int32
+
+// SetComplianceMode 开启后所有对外渲染(ToResponseBody/ToProblemDetails及WriteJSON/WriteProblem/WriteSSE,
+// contrib中的grpcstatus/kratos/gqlgen)都不包含堆栈、fnName、detail与channelMsg, 生产环境建议在启动时开启
+// 只影响对外渲染, 日志、执行器与MarshalJSON仍能拿到完整信息; kitex/dubbo/temporal等服务间传递不受影响
+func SetComplianceMode(on bool) {
+	var v int32
+	if on {
+		v = 1
+	}
+	atomic.StoreInt32(&complianceMode, v)
+}
+
+// ComplianceMode 是否开启了合规模式
+func ComplianceMode() bool {
+	return atomic.LoadInt32(&complianceMode) == 1
+}
+
+// ComplianceTranslator 返回标记为对外脱敏的副本, 未开启全局合规模式时也按合规模式渲染
+// 与StripInternalsTranslator不同, 副本本身保留全部字段, 之后的日志与上报不受影响
+func ComplianceTranslator() Translator {
+	return TranslatorFunc(func(ctx context.Context, e *SunError) *SunError {
+		if e.redact {
+			return e
+		}
+		out := e.clone()
+		out.redact = true
+		return out
+	})
+}
+
+// External 返回用于对外渲染的错误: 开启合规模式或经过ComplianceTranslator时返回去除内部信息的副本, 否则返回e本身
+// 内置的渲染函数与contrib适配层在序列化前都会调用, 自定义的响应渲染也应使用它
+func (e *SunError) External() *SunError {
+	if !e.redact && !ComplianceMode() {
+		return e
+	}
+	out := e.clone()
+	out.stripInternals()
+	return out
+}
+
+// stripInternals 去除detail/函数名/堆栈/下游错误信息, 只能在新的副本上调用
+func (e *SunError) stripInternals() {
+	e.detail = ""
+	e.lazyDetail = nil
+	e.fnName = ""
+	e.pc = 0
+	e.storeStack = false
+	e.stack = nil
+	e.stackPCs = nil
+	e.channelCode = ""
+	e.channelMsg = ""
+	e.channelCall = nil
+	e.remote = nil
+	e.payload = nil
+}
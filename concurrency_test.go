@@ -0,0 +1,91 @@
+package sunerror
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 这些测试在go test -race下验证SunError的并发模型, 见SunError的文档
+
+func TestConcurrentReaders(t *testing.T) {
+	resetAsyncPool(t)
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	exec := WithAsyncExecutor(func(ctx context.Context, e *SunError) {
+		_ = e.Error()
+		_ = e.GetDetail()
+		_, _ = e.MarshalJSON()
+	})
+	e := NewSunError(ctx, "RACE_1", "500", "concurrent", WithNoLogOption(), WithDetailOption("order=%d", 1), exec)
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			want := e.Error()
+			for j := 0; j < 100; j++ {
+				if got := e.Error(); got != want {
+					t.Errorf("Error() changed: %q != %q", got, want)
+					return
+				}
+				_ = e.GetFnName()
+				_ = e.GetErrorID()
+				_ = e.AppendError(nil)
+				_ = e.FormatStack(OneLineStack)
+				_ = e.AppendDetail("reader=%d", j)
+			}
+		}()
+	}
+	wg.Wait()
+	flushCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := Flush(flushCtx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReleaseKeepsDerivedCopies(t *testing.T) {
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				e := AcquireSunError(ctx, "RACE_POOL", "500", "pooled", WithNoLogOption(), WithStackRows(8))
+				derived := e.AppendDetail("copy")
+				want := derived.Error()
+				Release(e)
+				// 归还后的结构体会被其他协程复用, 派生的副本不能受影响
+				if got := derived.Error(); got != want || !strings.Contains(derived.GetDetail(), "copy") {
+					t.Errorf("derived copy changed after Release: %q", got)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestReleaseWithPendingAsync(t *testing.T) {
+	resetAsyncPool(t)
+	ctx := context.Background()
+	done := make(chan Code, 64)
+	for i := 0; i < 64; i++ {
+		e := AcquireSunError(ctx, "RACE_ASYNC", "500", "pooled async", WithNoLogOption(),
+			WithAsyncExecutor(func(ctx context.Context, e *SunError) {
+				time.Sleep(time.Millisecond)
+				done <- e.GetCode()
+			}))
+		// 已提交异步执行器, Release不回收, 执行器读到的内容不变
+		Release(e)
+		_ = AcquireSunError(ctx, "RACE_REUSE", "500", "reuse", WithNoLogOption())
+	}
+	for i := 0; i < 64; i++ {
+		if code := <-done; code != "RACE_ASYNC" {
+			t.Fatalf("async executor saw %q after Release", code)
+		}
+	}
+}
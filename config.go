@@ -0,0 +1,128 @@
+package sunerror
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Config 全局默认配置, 对之后构造的所有错误生效, 调用点的选项优先
+// Configure会使用全部字段, 零值字段不会回退到内置默认值, 修改部分字段时请从DefaultConfig()开始
+//
+//	cfg := sunerror.DefaultConfig()
+//	cfg.LogEngine = logger.CtxErrorf
+//	sunerror.Configure(cfg)
+type Config struct {
+	DefaultLevel      SunErrLevel                                                // 默认日志等级, 内置默认值为ErrorLevel, 零值为InfoLevel
+	DefaultStackRows  int                                                        // 默认保存的堆栈行数, <=0时使用10
+	StoreStackDefault bool                                                       // 默认是否保存堆栈, 内置默认值为true, 零值为不保存
+	LogEngine         func(ctx context.Context, format string, v ...interface{}) // 未设置WithLogEngine时使用的日志引擎
+	SkipDepthBase     int                                                        // 所有错误额外跳过的栈深度, 统一封装了NewSunError时设置
+	EnableDebug       bool                                                       // 是否打印DebugLevel的错误, 默认false
+	FuncFormat        FuncFormat                                                 // fnName的默认格式, 默认为文件名:行号:函数名()
+	FuncFormatter     func(frame runtime.Frame) string                           // 自定义的fnName格式化函数, 设置后忽略FuncFormat
+	Limits            Limits                                                     // detail等字段的大小上限, 默认不限制
+	DeadlineGuard     time.Duration                                              // ctx剩余时间低于该值时跳过堆栈与负载的抓取, 以标记代替, 默认0不启用
+	InternStrings     int                                                        // 驻留code/status/msg等重复字符串的最大数量, 长期保留大量错误时减少内存, 默认0不启用
+}
+
+// DefaultConfig 返回当前生效的全局配置, 未调用Configure时为内置默认值
+func DefaultConfig() Config {
+	return loadConfig().Config
+}
+
+// Configure 替换全局默认配置, 通常在main开始时调用
+// 包级变量中的Define早于Configure执行, 由定义产生的LightError的等级与堆栈设置以Define时的配置为准, 日志引擎以打印时为准
+func Configure(cfg Config) {
+	updateConfig(func(c *Config) { *c = cfg })
+}
+
+// updateConfig 在configMu下读取、修改并替换全局配置, 与Configure等修改串行执行
+func updateConfig(fn func(cfg *Config)) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	old := loadConfig()
+	cfg := old.Config
+	fn(&cfg)
+	if cfg.DefaultStackRows <= 0 {
+		cfg.DefaultStackRows = 10
+	}
+	globalConfig.Store(&config{Config: cfg, defaultOpts: old.defaultOpts})
+}
+
+// AddDefaultOptions 追加对所有新错误生效的选项, 在全局配置之后、调用点的选项之前应用
+// 如统一设置日志引擎、全局执行器等, 不需要每个调用点都传相同的选项
+func AddDefaultOptions(opts ...SunErrOption) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	old := loadConfig()
+	defaultOpts := make([]SunErrOption, 0, len(old.defaultOpts)+len(opts))
+	defaultOpts = append(append(defaultOpts, old.defaultOpts...), opts...)
+	globalConfig.Store(&config{Config: old.Config, defaultOpts: defaultOpts})
+}
+
+// ResetDefaultOptions 清除AddDefaultOptions追加的选项
+func ResetDefaultOptions() {
+	configMu.Lock()
+	defer configMu.Unlock()
+	globalConfig.Store(&config{Config: loadConfig().Config})
+}
+
+// config 全局配置的不可变快照, 每次修改都整体替换, 构造错误时只需一次原子读取
+type config struct {
+	Config
+	defaultOpts []SunErrOption
+}
+
+var (
+	configMu     sync.Mutex
+	globalConfig atomic.Value
+)
+
+func init() {
+	globalConfig.Store(&config{Config: Config{
+		DefaultLevel:      ErrorLevel,
+		DefaultStackRows:  10,
+		StoreStackDefault: true,
+	}})
+}
+
+func loadConfig() *config {
+	return globalConfig.Load().(*config)
+}
+
+// applyDefaults 按全局配置设置错误的默认值并应用默认选项
+func (e *SunError) applyDefaults(cfg *config) {
+	e.level = cfg.DefaultLevel
+	e.storeStack = cfg.StoreStackDefault
+	e.depth = 2 + cfg.SkipDepthBase
+	e.stackRows = cfg.DefaultStackRows
+	e.logEngine = cfg.LogEngine
+	e.fnFormat = cfg.FuncFormat
+	e.fnFormatter = cfg.FuncFormatter
+	for _, opt := range cfg.defaultOpts {
+		opt(e)
+	}
+}
+
+type ctxOptionsKey struct{}
+
+// ContextWithOptions 返回携带选项的ctx, 以该ctx(或其派生ctx)构造的每个错误都会应用这些选项
+// 选项在调用点的选项之后应用, 可用于在请求范围内强制覆盖, 如为排查中的某个用户打开堆栈与Info级别日志
+// 多次调用时按调用顺序累加
+func ContextWithOptions(ctx context.Context, opts ...SunErrOption) context.Context {
+	parent := contextOptions(ctx)
+	merged := make([]SunErrOption, 0, len(parent)+len(opts))
+	merged = append(append(merged, parent...), opts...)
+	return context.WithValue(ctx, ctxOptionsKey{}, merged)
+}
+
+func contextOptions(ctx context.Context) []SunErrOption {
+	if ctx == nil {
+		return nil
+	}
+	opts, _ := ctx.Value(ctxOptionsKey{}).([]SunErrOption)
+	return opts
+}
@@ -0,0 +1,182 @@
+package sunerror
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sync"
+)
+
+// ConsoleEngine 内置的文本日志引擎, 每条日志一行(堆栈与源码片段另起行), 适合本地开发与命令行工具
+// 通过WithLogEngine(engine.Log)或Config.LogEngine使用, 可以并发调用
+type ConsoleEngine struct {
+	mu      sync.Mutex
+	w       io.Writer
+	color   bool
+	snippet int
+	format  ConsoleFormat
+	layout  *Layout
+}
+
+// ConsoleFormat ConsoleEngine的输出格式
+type ConsoleFormat int8
+
+const (
+	// TextFormat 等级前缀加Error()的文本, 默认格式
+	TextFormat ConsoleFormat = iota
+	// LogfmtFormat logfmt格式(key=value), 适合Loki等按logfmt解析的日志管道
+	LogfmtFormat
+)
+
+// ConsoleOption ConsoleEngine的配置函数
+type ConsoleOption func(c *ConsoleEngine)
+
+// WithConsoleColor 按日志等级着色(Debug灰色/Info青色/Warn黄色/Error红色/Fatal紫色)
+func WithConsoleColor(color bool) ConsoleOption {
+	return func(c *ConsoleEngine) {
+		c.color = color
+	}
+}
+
+// WithConsoleFormat 设置输出格式, 默认为TextFormat
+// LogfmtFormat下每条日志只占一行, 不着色也不输出源码片段
+func WithConsoleFormat(format ConsoleFormat) ConsoleOption {
+	return func(c *ConsoleEngine) {
+		c.format = format
+	}
+}
+
+// WithConsoleLayout TextFormat下按layout格式化日志参数中的SunError, 不设置时使用Error()
+func WithConsoleLayout(layout *Layout) ConsoleOption {
+	return func(c *ConsoleEngine) {
+		c.layout = layout
+	}
+}
+
+// WithSourceSnippet 在错误之后输出产生错误的源码行及其前后lines行, 源文件不可读时忽略, 只用于开发环境
+func WithSourceSnippet(lines int) ConsoleOption {
+	return func(c *ConsoleEngine) {
+		c.snippet = lines
+	}
+}
+
+// NewConsoleEngine 创建写入w的文本日志引擎, w为nil时写入os.Stderr
+func NewConsoleEngine(w io.Writer, opts ...ConsoleOption) *ConsoleEngine {
+	if w == nil {
+		w = os.Stderr
+	}
+	c := &ConsoleEngine{w: w}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func levelColor(level SunErrLevel) string {
+	switch level {
+	case DebugLevel:
+		return "\x1b[90m"
+	case InfoLevel:
+		return "\x1b[36m"
+	case WarnLevel:
+		return "\x1b[33m"
+	case FatalLevel:
+		return "\x1b[35m"
+	}
+	return "\x1b[31m"
+}
+
+const colorReset = "\x1b[0m"
+
+// Log 日志引擎函数, 参数中包含SunError时按其等级输出前缀
+func (c *ConsoleEngine) Log(ctx context.Context, format string, v ...interface{}) {
+	e := sunErrorArg(v)
+	level := ErrorLevel
+	if e != nil {
+		level = e.level
+	}
+	if c.format == LogfmtFormat {
+		c.logfmt(e, format, v)
+		return
+	}
+	line := fmt.Sprintf(format, layoutArgs(c.layout, v)...)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.color {
+		fmt.Fprintf(c.w, "%s%-5s%s %s\n", levelColor(level), levelName(level), colorReset, line)
+	} else {
+		fmt.Fprintf(c.w, "%-5s %s\n", levelName(level), line)
+	}
+	if e != nil && c.snippet > 0 && e.pc != 0 {
+		writeSnippet(c.w, e.pc, c.snippet)
+	}
+}
+
+// logfmt 参数中包含SunError时输出它的全部字段, 否则输出level=error与格式化后的msg
+func (c *ConsoleEngine) logfmt(e *SunError, format string, v []interface{}) {
+	buf := make([]byte, 0, 256)
+	if e != nil {
+		buf = e.AppendLogfmt(buf)
+	} else {
+		buf = appendLogfmtPair(buf, "level", ErrorLevel.String())
+		buf = appendLogfmtPair(buf, "msg", fmt.Sprintf(format, v...))
+	}
+	buf = append(buf, '\n')
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.w.Write(buf)
+}
+
+// sunErrorArg 返回日志参数中的SunError, 没有时返回nil
+func sunErrorArg(v []interface{}) *SunError {
+	for _, arg := range v {
+		if e, ok := arg.(*SunError); ok {
+			return e
+		}
+	}
+	return nil
+}
+
+func levelName(level SunErrLevel) string {
+	switch level {
+	case DebugLevel:
+		return "DEBUG"
+	case InfoLevel:
+		return "INFO"
+	case WarnLevel:
+		return "WARN"
+	case FatalLevel:
+		return "FATAL"
+	}
+	return "ERROR"
+}
+
+// writeSnippet 输出pc所在源码行及前后lines行, 当前行以>标记
+func writeSnippet(w io.Writer, pc uintptr, lines int) {
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if len(frame.File) == 0 || frame.Line <= 0 {
+		return
+	}
+	f, err := os.Open(frame.File)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	first, last := frame.Line-lines, frame.Line+lines
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan() && n <= last; n++ {
+		if n < first {
+			continue
+		}
+		mark := "  "
+		if n == frame.Line {
+			mark = "> "
+		}
+		fmt.Fprintf(w, "    %s%4d| %s\n", mark, n, scanner.Text())
+	}
+}
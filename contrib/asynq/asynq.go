@@ -0,0 +1,29 @@
+// Package asynq 提供asynq的消费中间件, 按contrib/mq的策略决定重试或归档(asynq的死信)
+package asynq
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/sjmshsh/sunerror/contrib/mq"
+)
+
+// Middleware 返回asynq中间件, 通过mux.Use注册
+// 最大尝试次数取自任务的MaxRetry, 处置为DeadLetter时以asynq.SkipRetry跳过剩余重试直接归档
+func Middleware(opts ...mq.Option) asynq.MiddlewareFunc {
+	return func(h asynq.Handler) asynq.Handler {
+		return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) error {
+			retried, _ := asynq.GetRetryCount(ctx)
+			maxRetry, _ := asynq.GetMaxRetry(ctx)
+			fields := append([]mq.Option{mq.WithMaxAttempts(maxRetry + 1)}, opts...)
+			decision, err := mq.Handle(ctx, t.Type(), retried+1, func(ctx context.Context) error {
+				return h.ProcessTask(ctx, t)
+			}, fields...)
+			if decision == mq.DeadLetter {
+				return fmt.Errorf("%w: %w", err, asynq.SkipRetry)
+			}
+			return err
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// Package awserr 为sunerror.FromError识别AWS SDK v2的错误(smithy.APIError), 包括S3等兼容S3协议的服务
+package awserr
+
+import (
+	"errors"
+
+	"github.com/aws/smithy-go"
+	"github.com/sjmshsh/sunerror"
+)
+
+// Name 注册分类器使用的名称
+const Name = "aws"
+
+// 识别结果使用的错误码
+const (
+	ErrorCode        sunerror.Code = "AWS_ERROR"
+	NotFoundCode     sunerror.Code = "AWS_NOT_FOUND"
+	AccessDeniedCode sunerror.Code = "AWS_ACCESS_DENIED"
+	ThrottledCode    sunerror.Code = "AWS_THROTTLED"
+)
+
+type codeInfo struct {
+	code      sunerror.Code
+	kind      sunerror.SunErrKind
+	retryable bool
+}
+
+// errorCodes AWS服务返回的错误码
+var errorCodes = map[string]codeInfo{
+	"NoSuchKey":                              {NotFoundCode, sunerror.NotFoundKind, false},
+	"NoSuchBucket":                           {NotFoundCode, sunerror.NotFoundKind, false},
+	"NotFound":                               {NotFoundCode, sunerror.NotFoundKind, false},
+	"ResourceNotFoundException":              {NotFoundCode, sunerror.NotFoundKind, false},
+	"AccessDenied":                           {AccessDeniedCode, sunerror.PermissionDeniedKind, false},
+	"AccessDeniedException":                  {AccessDeniedCode, sunerror.PermissionDeniedKind, false},
+	"ExpiredToken":                           {ErrorCode, sunerror.UnauthenticatedKind, false},
+	"ExpiredTokenException":                  {ErrorCode, sunerror.UnauthenticatedKind, false},
+	"InvalidAccessKeyId":                     {ErrorCode, sunerror.UnauthenticatedKind, false},
+	"InvalidClientTokenId":                   {ErrorCode, sunerror.UnauthenticatedKind, false},
+	"SignatureDoesNotMatch":                  {ErrorCode, sunerror.UnauthenticatedKind, false},
+	"UnrecognizedClientException":            {ErrorCode, sunerror.UnauthenticatedKind, false},
+	"ValidationException":                    {ErrorCode, sunerror.ValidationKind, false},
+	"InvalidParameterValue":                  {ErrorCode, sunerror.ValidationKind, false},
+	"ConditionalCheckFailedException":        {ErrorCode, sunerror.ConflictKind, false},
+	"PreconditionFailed":                     {ErrorCode, sunerror.ConflictKind, false},
+	"BucketAlreadyExists":                    {ErrorCode, sunerror.ConflictKind, false},
+	"Throttling":                             {ThrottledCode, sunerror.TransientKind, true},
+	"ThrottlingException":                    {ThrottledCode, sunerror.TransientKind, true},
+	"TooManyRequestsException":               {ThrottledCode, sunerror.TransientKind, true},
+	"RequestLimitExceeded":                   {ThrottledCode, sunerror.TransientKind, true},
+	"ProvisionedThroughputExceededException": {ThrottledCode, sunerror.TransientKind, true},
+	"SlowDown":                               {ThrottledCode, sunerror.TransientKind, true},
+	"RequestTimeout":                         {ErrorCode, sunerror.TimeoutKind, true},
+	"RequestTimeoutException":                {ErrorCode, sunerror.TimeoutKind, true},
+	"ServiceUnavailable":                     {ErrorCode, sunerror.DownstreamKind, true},
+	"InternalError":                          {ErrorCode, sunerror.DownstreamKind, true},
+}
+
+// Register 注册AWS错误分类器, 在初始化时调用一次
+func Register() {
+	sunerror.RegisterErrorClassifier(Name, Classify)
+}
+
+// Classify sunerror.ErrorClassifier实现, 按AWS错误码识别; 未知错误码中服务端故障(FaultServer)为可重试的下游错误
+// 原始错误码与错误信息记录为下游错误码/信息
+func Classify(err error) (sunerror.SDKError, bool) {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return sunerror.SDKError{}, false
+	}
+	info := sunerror.SDKError{
+		Code:        ErrorCode,
+		Kind:        sunerror.DownstreamKind,
+		ChannelCode: apiErr.ErrorCode(),
+		ChannelMsg:  apiErr.ErrorMessage(),
+	}
+	if c, ok := errorCodes[apiErr.ErrorCode()]; ok {
+		info.Code, info.Kind, info.Retryable = c.code, c.kind, c.retryable
+	} else if apiErr.ErrorFault() == smithy.FaultServer {
+		info.Retryable = true
+	} else if apiErr.ErrorFault() == smithy.FaultClient {
+		info.Kind = sunerror.ValidationKind
+	}
+	return info, true
+}
@@ -0,0 +1,10 @@
+module github.com/sjmshsh/sunerror/contrib/awserr
+
+go 1.24
+
+replace github.com/sjmshsh/sunerror => ../..
+
+require (
+	github.com/aws/smithy-go v1.28.2
+	github.com/sjmshsh/sunerror v0.0.0-00010101000000-000000000000
+)
@@ -0,0 +1,40 @@
+// Package cbor 以CBOR(RFC 8949)编码SunError, 字段与MarshalJSON一致(cbor库沿用sunerror.Record的json标签), 适合二进制MQ负载
+package cbor
+
+import (
+	"github.com/fxamacker/cbor/v2"
+	"github.com/sjmshsh/sunerror"
+)
+
+// Marshal 编码全部可跨进程传递的字段, withStack为false时不包含堆栈
+func Marshal(e *sunerror.SunError, withStack bool) ([]byte, error) {
+	return cbor.Marshal(e.ToRecord(withStack))
+}
+
+// Unmarshal 从Marshal的结果还原SunError, 还原时不打印日志也不执行执行器
+func Unmarshal(data []byte) (*sunerror.SunError, error) {
+	var r sunerror.Record
+	if err := cbor.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+	return sunerror.FromRecord(r)
+}
+
+// Format 注册到sunerror的序列化格式名
+const Format = "cbor"
+
+// Register 以Format注册为sunerror.Serializer(包含堆栈), 在初始化时调用一次
+func Register() {
+	sunerror.RegisterSerializer(Format, Serializer{})
+}
+
+// Serializer sunerror.Serializer实现
+type Serializer struct{}
+
+func (Serializer) Encode(e *sunerror.SunError) ([]byte, error) {
+	return Marshal(e, true)
+}
+
+func (Serializer) Decode(data []byte) (*sunerror.SunError, error) {
+	return Unmarshal(data)
+}
@@ -0,0 +1,12 @@
+module github.com/sjmshsh/sunerror/contrib/cbor
+
+go 1.22.0
+
+replace github.com/sjmshsh/sunerror => ../..
+
+require (
+	github.com/fxamacker/cbor/v2 v2.9.4
+	github.com/sjmshsh/sunerror v0.0.0-00010101000000-000000000000
+)
+
+require github.com/x448/float16 v0.8.4 // indirect
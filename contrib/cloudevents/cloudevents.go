@@ -0,0 +1,67 @@
+// Package cloudevents 将SunError转换为CloudEvents 1.0事件, 并提供发布事件的异步执行器, 供事件驱动的错误处理管道消费
+package cloudevents
+
+import (
+	"context"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/sjmshsh/sunerror"
+)
+
+// TypePrefix 事件type的前缀, type为TypePrefix + code
+var TypePrefix = "com.sjmshsh.sunerror."
+
+// 扩展属性名, CloudEvents要求只包含小写字母与数字
+const (
+	ExtLevel   = "errlevel"
+	ExtKind    = "errkind"
+	ExtTraceID = "errtraceid"
+)
+
+// ToEvent 将SunError转换为CloudEvents事件
+// id为errorID, type为TypePrefix + code, subject为fnName, data为sunerror.Record(application/json, 包含堆栈)
+// level/kind/traceID以扩展属性携带, 便于不解析data直接路由与过滤
+func ToEvent(e *sunerror.SunError, source string) (cloudevents.Event, error) {
+	event := cloudevents.NewEvent()
+	event.SetID(e.GetErrorID())
+	event.SetSource(source)
+	event.SetType(TypePrefix + e.GetCode().String())
+	event.SetSubject(e.GetFnName())
+	event.SetTime(time.Now())
+	event.SetExtension(ExtLevel, e.GetLevel().String())
+	if kind := e.GetKind(); kind != sunerror.UnknownKind {
+		event.SetExtension(ExtKind, kind.String())
+	}
+	if traceID := e.GetTraceID(); len(traceID) > 0 {
+		event.SetExtension(ExtTraceID, traceID)
+	}
+	if err := event.SetData(cloudevents.ApplicationJSON, e.ToRecord(true)); err != nil {
+		return event, err
+	}
+	return event, event.Validate()
+}
+
+// FromEvent 从ToEvent产生的事件还原SunError, 还原时不打印日志也不执行执行器
+func FromEvent(event cloudevents.Event) (*sunerror.SunError, error) {
+	var r sunerror.Record
+	if err := event.DataAs(&r); err != nil {
+		return nil, err
+	}
+	return sunerror.FromRecord(r)
+}
+
+// Executor 返回发布事件的执行器, 通过sunerror.WithRetryableAsyncExecutor注册
+// 发送失败(含接收方NACK)时返回错误, 按WithAsyncRetry的设置重试
+func Executor(client cloudevents.Client, source string) func(context.Context, *sunerror.SunError) error {
+	return func(ctx context.Context, e *sunerror.SunError) error {
+		event, err := ToEvent(e, source)
+		if err != nil {
+			return err
+		}
+		if result := client.Send(ctx, event); !cloudevents.IsACK(result) {
+			return result
+		}
+		return nil
+	}
+}
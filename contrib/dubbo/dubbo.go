@@ -0,0 +1,111 @@
+// Package dubbo 提供dubbo-go的服务端/客户端Filter, 通过响应attachments传递SunError
+// 服务端Filter将SunError写入attachments, 客户端Filter再从attachments还原SunError
+package dubbo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/filter"
+	"dubbo.apache.org/dubbo-go/v3/protocol"
+	"github.com/sjmshsh/sunerror"
+)
+
+// Filter注册名, 在配置的filter列表中引用
+const (
+	ServerFilterName = "sunerror_server"
+	ClientFilterName = "sunerror_client"
+)
+
+// attachments中使用的key
+const (
+	attachCode        = "sunerror.code"
+	attachStatus      = "sunerror.status"
+	attachMsg         = "sunerror.msg"
+	attachDetail      = "sunerror.detail"
+	attachFnName      = "sunerror.fnName"
+	attachChannelCode = "sunerror.channelCode"
+	attachChannelMsg  = "sunerror.channelMsg"
+	attachErrorID     = "sunerror.errorID"
+)
+
+func init() {
+	extension.SetFilter(ServerFilterName, func() filter.Filter { return &serverFilter{} })
+	extension.SetFilter(ClientFilterName, func() filter.Filter { return &clientFilter{} })
+}
+
+type serverFilter struct{}
+
+func (f *serverFilter) Invoke(ctx context.Context, invoker protocol.Invoker, invocation protocol.Invocation) protocol.Result {
+	return invoker.Invoke(ctx, invocation)
+}
+
+// OnResponse 将SunError写入响应attachments, 异常信息为code与msg
+func (f *serverFilter) OnResponse(ctx context.Context, result protocol.Result, invoker protocol.Invoker, invocation protocol.Invocation) protocol.Result {
+	var sunErr *sunerror.SunError
+	if !errors.As(result.Error(), &sunErr) {
+		return result
+	}
+	result.AddAttachment(attachCode, sunErr.GetCode().String())
+	result.AddAttachment(attachStatus, sunErr.GetStatus())
+	result.AddAttachment(attachMsg, sunErr.GetMsg())
+	result.AddAttachment(attachDetail, sunErr.GetDetail())
+	result.AddAttachment(attachFnName, sunErr.GetFnName())
+	result.AddAttachment(attachChannelCode, sunErr.GetChannelCode())
+	result.AddAttachment(attachChannelMsg, sunErr.GetChannelMsg())
+	result.AddAttachment(attachErrorID, sunErr.GetErrorID())
+	result.SetError(fmt.Errorf("code=%s, msg=%s", sunErr.GetCode(), sunErr.GetMsg()))
+	return result
+}
+
+type clientFilter struct{}
+
+func (f *clientFilter) Invoke(ctx context.Context, invoker protocol.Invoker, invocation protocol.Invocation) protocol.Result {
+	return invoker.Invoke(ctx, invocation)
+}
+
+// OnResponse 从响应attachments还原SunError并替换调用返回的异常
+func (f *clientFilter) OnResponse(ctx context.Context, result protocol.Result, invoker protocol.Invoker, invocation protocol.Invocation) protocol.Result {
+	if result.Error() == nil {
+		return result
+	}
+	if sunErr := FromResult(ctx, result); sunErr != nil {
+		result.SetError(sunErr)
+	}
+	return result
+}
+
+// FromResult 从调用结果的attachments还原SunError, 没有SunError信息时返回nil
+// 还原时不打印日志也不保存本地堆栈
+func FromResult(ctx context.Context, result protocol.Result, opts ...sunerror.SunErrOption) *sunerror.SunError {
+	code := attachment(result, attachCode)
+	if len(code) == 0 {
+		return nil
+	}
+	fields := []sunerror.SunErrOption{
+		sunerror.WithNoLogOption(),
+		sunerror.WithStackOption(false),
+		sunerror.WithRestoredOption(),
+		sunerror.WithSkipDepthOption(1),
+		sunerror.WithRemoteOption(attachment(result, attachFnName), ""),
+		sunerror.WithDetailOption("%s", attachment(result, attachDetail)),
+		sunerror.WithChannelRespOption(attachment(result, attachChannelCode), attachment(result, attachChannelMsg)),
+		sunerror.WithErrorIDOption(attachment(result, attachErrorID)),
+	}
+	return sunerror.NewSunError(ctx, sunerror.Code(code), attachment(result, attachStatus), attachment(result, attachMsg), append(fields, opts...)...)
+}
+
+// attachment 读取字符串attachment, 兼容部分协议将值解码为[]string的情况
+func attachment(result protocol.Result, key string) string {
+	switch v := result.Attachment(key, "").(type) {
+	case string:
+		return v
+	case []string:
+		if len(v) > 0 {
+			return v[0]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,20 @@
+// Package gobreaker 让sony/gobreaker只把服务端/下游类的SunError计为失败
+package gobreaker
+
+import (
+	"github.com/sjmshsh/sunerror"
+	"github.com/sony/gobreaker"
+)
+
+// IsSuccessful gobreaker.Settings.IsSuccessful的实现, 按sunerror.IsBreakerFailure判定
+func IsSuccessful(err error) bool {
+	return !sunerror.IsBreakerFailure(err)
+}
+
+// NewCircuitBreaker 创建熔断器, 未设置IsSuccessful时使用本包的判定
+func NewCircuitBreaker(st gobreaker.Settings) *gobreaker.CircuitBreaker {
+	if st.IsSuccessful == nil {
+		st.IsSuccessful = IsSuccessful
+	}
+	return gobreaker.NewCircuitBreaker(st)
+}
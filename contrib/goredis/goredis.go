@@ -0,0 +1,55 @@
+// Package goredis 为sunerror.FromError识别redis/go-redis的错误
+package goredis
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sjmshsh/sunerror"
+)
+
+// Name 注册分类器使用的名称
+const Name = "redis"
+
+// 识别结果使用的错误码
+const (
+	ErrorCode sunerror.Code = "REDIS_ERROR"
+	NilCode   sunerror.Code = "REDIS_NIL"
+)
+
+// Register 注册go-redis错误分类器, 在初始化时调用一次
+func Register() {
+	sunerror.RegisterErrorClassifier(Name, Classify)
+}
+
+// Classify sunerror.ErrorClassifier实现
+// 1. redis.Nil(key不存在)为NilCode, NotFoundKind
+// 2. 服务端返回的错误按前缀识别: LOADING/READONLY/CLUSTERDOWN/TRYAGAIN/MASTERDOWN/BUSY可重试, NOAUTH/WRONGPASS未认证, NOPERM无权限
+// 3. redis.ErrClosed为可重试的临时故障
+func Classify(err error) (sunerror.SDKError, bool) {
+	switch {
+	case errors.Is(err, redis.Nil):
+		return sunerror.SDKError{Code: NilCode, Msg: "redis key not found", Kind: sunerror.NotFoundKind}, true
+	case errors.Is(err, redis.ErrClosed):
+		return sunerror.SDKError{Code: ErrorCode, Kind: sunerror.TransientKind, Retryable: true}, true
+	}
+	var redisErr redis.Error
+	if !errors.As(err, &redisErr) {
+		return sunerror.SDKError{}, false
+	}
+	msg := redisErr.Error()
+	prefix, _, _ := strings.Cut(msg, " ")
+	info := sunerror.SDKError{Code: ErrorCode, Kind: sunerror.DownstreamKind, ChannelCode: prefix, ChannelMsg: msg}
+	switch prefix {
+	case "LOADING", "READONLY", "CLUSTERDOWN", "TRYAGAIN", "MASTERDOWN", "BUSY":
+		info.Kind, info.Retryable = sunerror.TransientKind, true
+	case "NOAUTH", "WRONGPASS":
+		info.Kind = sunerror.UnauthenticatedKind
+	case "NOPERM":
+		info.Kind = sunerror.PermissionDeniedKind
+	case "WRONGTYPE", "ERR":
+		info.Kind = sunerror.InternalKind
+	}
+	return info, true
+}
@@ -0,0 +1,63 @@
+// Package gozero 提供SunError与go-zero错误约定(x/errors.CodeMsg与httpx错误处理)之间的转换
+package gozero
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"strconv"
+
+	"github.com/sjmshsh/sunerror"
+	"github.com/zeromicro/x/errors"
+)
+
+// CodeMapper 将SunError的字符串错误码映射为CodeMsg的整型错误码
+// 默认解析数字错误码, 无法解析时返回-1
+var CodeMapper = func(e *sunerror.SunError) int {
+	code, err := strconv.Atoi(e.GetCode().String())
+	if err != nil {
+		return -1
+	}
+	return code
+}
+
+// HTTPStatusMapper 决定httpx错误处理返回的HTTP状态码, 默认为SunError.HTTPStatus()
+var HTTPStatusMapper = func(e *sunerror.SunError) int {
+	return e.HTTPStatus()
+}
+
+// ToCodeMsg 将SunError转换为go-zero的CodeMsg
+func ToCodeMsg(e *sunerror.SunError) *errors.CodeMsg {
+	return &errors.CodeMsg{Code: CodeMapper(e), Msg: e.GetMsg()}
+}
+
+// FromCodeMsg 将go-zero的CodeMsg还原为SunError, err不是CodeMsg时返回nil
+// CodeMsg只有code/msg, status使用code填充; 还原时不打印日志也不保存本地堆栈
+func FromCodeMsg(ctx context.Context, err error, opts ...sunerror.SunErrOption) *sunerror.SunError {
+	var cm *errors.CodeMsg
+	if !stderrors.As(err, &cm) {
+		return nil
+	}
+	code := strconv.Itoa(cm.Code)
+	fields := []sunerror.SunErrOption{
+		sunerror.WithNoLogOption(),
+		sunerror.WithStackOption(false),
+		sunerror.WithRestoredOption(),
+	}
+	return sunerror.NewSunError(ctx, sunerror.Code(code), code, cm.Msg, append(fields, opts...)...)
+}
+
+// ErrorHandlerCtx httpx.SetErrorHandlerCtx的实现, SunError渲染为sunerror.ResponseBody
+// 其他error交给fallback处理, fallback为nil时返回500与err.Error()
+func ErrorHandlerCtx(fallback func(context.Context, error) (int, any)) func(context.Context, error) (int, any) {
+	return func(ctx context.Context, err error) (int, any) {
+		var sunErr *sunerror.SunError
+		if stderrors.As(err, &sunErr) {
+			return HTTPStatusMapper(sunErr), sunErr.ToResponseBody()
+		}
+		if fallback != nil {
+			return fallback(ctx, err)
+		}
+		return http.StatusInternalServerError, err.Error()
+	}
+}
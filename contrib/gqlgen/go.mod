@@ -0,0 +1,17 @@
+module github.com/sjmshsh/sunerror/contrib/gqlgen
+
+go 1.26
+
+replace github.com/sjmshsh/sunerror => ../..
+
+require (
+	github.com/99designs/gqlgen v0.17.95
+	github.com/sjmshsh/sunerror v0.0.0-00010101000000-000000000000
+	github.com/vektah/gqlparser/v2 v2.5.58
+)
+
+require (
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/sosodev/duration v1.4.0 // indirect
+	golang.org/x/sync v0.22.0 // indirect
+)
@@ -0,0 +1,58 @@
+// Package gqlgen 提供gqlgen的ErrorPresenter与RecoverFunc, 将SunError映射为带extensions的GraphQL错误
+package gqlgen
+
+import (
+	"context"
+	"errors"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/sjmshsh/sunerror"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// internalMsg 生产模式下非SunError的对外提示
+const internalMsg = "internal system error"
+
+// ErrorPresenter 返回graphql.ErrorPresenterFunc, 通过srv.SetErrorPresenter注册
+// extensions中总是包含code/errorID/retryable; production为true时不暴露status/detail/下游信息, 非SunError的消息也会被隐藏
+// 开启sunerror.SetComplianceMode时等同于production为true
+func ErrorPresenter(production bool) graphql.ErrorPresenterFunc {
+	return func(ctx context.Context, err error) *gqlerror.Error {
+		production := production || sunerror.ComplianceMode()
+		gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+		var sunErr *sunerror.SunError
+		if !errors.As(err, &sunErr) {
+			if production {
+				gqlErr.Message = internalMsg
+			}
+			return gqlErr
+		}
+		sunErr = sunErr.External()
+		gqlErr.Message = sunErr.GetMsg()
+		if gqlErr.Extensions == nil {
+			gqlErr.Extensions = make(map[string]interface{})
+		}
+		gqlErr.Extensions["code"] = sunErr.GetCode()
+		gqlErr.Extensions["errorID"] = sunErr.GetErrorID()
+		gqlErr.Extensions["retryable"] = sunErr.IsRetryable()
+		if !production {
+			gqlErr.Extensions["status"] = sunErr.GetStatus()
+			gqlErr.Extensions["detail"] = sunErr.GetDetail()
+			gqlErr.Extensions["channelCode"] = sunErr.GetChannelCode()
+			gqlErr.Extensions["channelMsg"] = sunErr.GetChannelMsg()
+		}
+		return gqlErr
+	}
+}
+
+// RecoverFunc 返回graphql.RecoverFunc, 通过srv.SetRecoverFunc注册
+// resolver发生panic时以code/status/msg构造SunError(打印日志及panic堆栈), 再交给ErrorPresenter渲染
+func RecoverFunc(code sunerror.Code, status, msg string, opts ...sunerror.SunErrOption) graphql.RecoverFunc {
+	return func(ctx context.Context, p interface{}) error {
+		fields := []sunerror.SunErrOption{
+			sunerror.WithDetailOption("panic: %v", p),
+			sunerror.WithStackRows(32),
+		}
+		return sunerror.NewSunError(ctx, code, status, msg, append(fields, opts...)...)
+	}
+}
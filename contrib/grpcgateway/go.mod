@@ -0,0 +1,23 @@
+module github.com/sjmshsh/sunerror/contrib/grpcgateway
+
+go 1.26.0
+
+replace github.com/sjmshsh/sunerror => ../..
+
+replace github.com/sjmshsh/sunerror/contrib/grpcstatus => ../grpcstatus
+
+require (
+	github.com/grpc-ecosystem/grpc-gateway/v2 v2.31.0
+	github.com/sjmshsh/sunerror v0.0.0-00010101000000-000000000000
+	github.com/sjmshsh/sunerror/contrib/grpcstatus v0.0.0-00010101000000-000000000000
+	google.golang.org/grpc v1.84.0
+)
+
+require (
+	golang.org/x/net v0.59.0 // indirect
+	golang.org/x/sys v0.48.0 // indirect
+	golang.org/x/text v0.42.0 // indirect
+	google.golang.org/genproto/googleapis/api v0.0.0-20260921155816-b14227669459 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260921155816-b14227669459 // indirect
+	google.golang.org/protobuf v1.36.12 // indirect
+)
@@ -0,0 +1,26 @@
+// Package grpcgateway 提供grpc-gateway的自定义错误处理, 让REST调用方得到与HTTP中间件一致的错误响应体
+package grpcgateway
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/sjmshsh/sunerror"
+	"github.com/sjmshsh/sunerror/contrib/grpcstatus"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorHandler runtime.ErrorHandlerFunc实现, 通过runtime.WithErrorHandler(ErrorHandler)注册
+// 能从errdetails中还原SunError时渲染sunerror.ResponseBody, 否则交给runtime.DefaultHTTPErrorHandler
+func ErrorHandler(ctx context.Context, mux *runtime.ServeMux, m runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	st := status.Convert(err)
+	sunErr := grpcstatus.FromStatus(ctx, st)
+	if sunErr == nil {
+		runtime.DefaultHTTPErrorHandler(ctx, mux, m, w, r, err)
+		return
+	}
+	_ = sunerror.WriteJSON(w, runtime.HTTPStatusFromCode(st.Code()), sunErr)
+}
+
+var _ runtime.ErrorHandlerFunc = ErrorHandler
@@ -0,0 +1,14 @@
+module github.com/sjmshsh/sunerror/contrib/grpcstatus
+
+go 1.26.0
+
+replace github.com/sjmshsh/sunerror => ../..
+
+require (
+	github.com/sjmshsh/sunerror v0.0.0-00010101000000-000000000000
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260921155816-b14227669459
+	google.golang.org/grpc v1.84.0
+	google.golang.org/protobuf v1.36.12
+)
+
+require golang.org/x/sys v0.47.0 // indirect
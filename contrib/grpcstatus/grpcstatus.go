@@ -0,0 +1,176 @@
+// Package grpcstatus 提供SunError与gRPC status之间的转换, SunError以errdetails.ErrorInfo的形式携带
+package grpcstatus
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/sjmshsh/sunerror"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/runtime/protoiface"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Domain ErrorInfo中标识SunError的domain
+const Domain = "sunerror"
+
+// ErrorInfo.Metadata中使用的key
+const (
+	metaStatus      = "status"
+	metaMsg         = "msg"
+	metaDetail      = "detail"
+	metaFnName      = "fnName"
+	metaStack       = "stack"
+	metaChannelCode = "channelCode"
+	metaChannelMsg  = "channelMsg"
+	metaErrorID     = "errorID"
+	metaUserMsg     = "userMsg"
+	metaDocsURL     = "docsURL"
+	metaKind        = "kind"
+	metaRetryable   = "retryable"
+	metaLevel       = "level"
+	metaSideEffect  = "sideEffect"
+)
+
+// CodeMapper 决定SunError对应的gRPC code, 默认为codes.Unknown
+var CodeMapper = func(e *sunerror.SunError) codes.Code {
+	return codes.Unknown
+}
+
+// ToStatus 将SunError转换为gRPC status, 三元组、下游信息、fnName与堆栈写入ErrorInfo, 建议重试间隔写入RetryInfo,
+// 字段校验失败明细写入BadRequest(BadRequest没有规则名, 还原后Rule为空)
+// 合规模式下不包含fnName/堆栈/detail/下游信息, 见sunerror.SunError.External
+func ToStatus(e *sunerror.SunError) *status.Status {
+	e = e.External()
+	st := status.New(CodeMapper(e), e.GetMsg())
+	info := &errdetails.ErrorInfo{
+		Reason: e.GetCode().String(),
+		Domain: Domain,
+		Metadata: map[string]string{
+			metaStatus:      e.GetStatus(),
+			metaMsg:         e.GetMsg(),
+			metaDetail:      e.GetDetail(),
+			metaFnName:      e.GetFnName(),
+			metaStack:       e.FormatStack(nil),
+			metaChannelCode: e.GetChannelCode(),
+			metaChannelMsg:  e.GetChannelMsg(),
+			metaErrorID:     e.GetErrorID(),
+			metaUserMsg:     e.GetUserMsg(),
+			metaDocsURL:     e.GetDocsURL(),
+			metaKind:        e.GetKind().String(),
+			metaRetryable:   strconv.FormatBool(e.IsRetryable()),
+			metaLevel:       e.GetLevel().String(),
+			metaSideEffect:  strconv.FormatBool(e.HasSideEffect()),
+		},
+	}
+	details := []protoiface.MessageV1{info}
+	if retryAfter, ok := e.GetRetryAfter(); ok {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)})
+	}
+	if violations := e.GetViolations(); len(violations) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, v := range violations {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       v.Field,
+				Description: v.Message,
+			})
+		}
+		details = append(details, badRequest)
+	}
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		return withDetails
+	}
+	return st
+}
+
+// FromStatus 从gRPC status的ErrorInfo中还原SunError, 不是SunError时返回nil
+// 还原时不打印日志也不保存本地堆栈; fnName为调用FromStatus的位置, 远端的fnName与堆栈单独保存, 见SunError.GetRemote
+func FromStatus(ctx context.Context, st *status.Status, opts ...sunerror.SunErrOption) *sunerror.SunError {
+	return fromStatus(ctx, st, opts)
+}
+
+// FromError 从gRPC调用返回的error中还原SunError, 不是SunError时返回nil
+func FromError(ctx context.Context, err error, opts ...sunerror.SunErrOption) *sunerror.SunError {
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil
+	}
+	return fromStatus(ctx, st, opts)
+}
+
+// fromStatus 由FromStatus/FromError直接调用, fnName跳过这两层指向它们的调用方
+func fromStatus(ctx context.Context, st *status.Status, opts []sunerror.SunErrOption) *sunerror.SunError {
+	info := ErrorInfo(st)
+	if info == nil {
+		return nil
+	}
+	md := info.GetMetadata()
+	fields := []sunerror.SunErrOption{
+		sunerror.WithNoLogOption(),
+		sunerror.WithStackOption(false),
+		sunerror.WithRestoredOption(),
+		sunerror.WithSkipDepthOption(2),
+		sunerror.WithRemoteOption(md[metaFnName], md[metaStack]),
+		sunerror.WithDetailOption("%s", md[metaDetail]),
+		sunerror.WithChannelRespOption(md[metaChannelCode], md[metaChannelMsg]),
+		sunerror.WithErrorIDOption(md[metaErrorID]),
+		sunerror.WithUserMsgOption(md[metaUserMsg]),
+		sunerror.WithDocsURLOption(md[metaDocsURL]),
+	}
+	if kind, ok := sunerror.ParseKind(md[metaKind]); ok {
+		fields = append(fields, sunerror.WithKindOption(kind))
+	}
+	if retryable, err := strconv.ParseBool(md[metaRetryable]); err == nil {
+		fields = append(fields, sunerror.WithRetryableOption(retryable))
+	}
+	if sideEffect, err := strconv.ParseBool(md[metaSideEffect]); err == nil {
+		fields = append(fields, sunerror.WithSideEffectOption(sideEffect))
+	}
+	if level, ok := sunerror.ParseLevel(md[metaLevel]); ok {
+		fields = append(fields, sunerror.WithLogLevelOption(level))
+	}
+	if violations := fieldViolations(st); len(violations) > 0 {
+		fields = append(fields, sunerror.WithViolationsOption(violations...), sunerror.WithDetailOption("%s", md[metaDetail]))
+	}
+	if retryInfo := RetryInfo(st); retryInfo != nil && retryInfo.GetRetryDelay() != nil {
+		fields = append(fields, sunerror.WithRetryAfterOption(retryInfo.GetRetryDelay().AsDuration()))
+	}
+	return sunerror.NewSunError(ctx, sunerror.Code(info.GetReason()), md[metaStatus], md[metaMsg], append(fields, opts...)...)
+}
+
+// ErrorInfo 返回status中domain为sunerror的ErrorInfo, 不存在时返回nil
+func ErrorInfo(st *status.Status) *errdetails.ErrorInfo {
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok && info.GetDomain() == Domain {
+			return info
+		}
+	}
+	return nil
+}
+
+// RetryInfo 返回status中的RetryInfo, 不存在时返回nil; 非SunError的status也可能携带
+func RetryInfo(st *status.Status) *errdetails.RetryInfo {
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.RetryInfo); ok {
+			return info
+		}
+	}
+	return nil
+}
+
+// fieldViolations 从status的BadRequest中还原字段校验失败明细
+func fieldViolations(st *status.Status) []sunerror.FieldViolation {
+	var violations []sunerror.FieldViolation
+	for _, d := range st.Details() {
+		badRequest, ok := d.(*errdetails.BadRequest)
+		if !ok {
+			continue
+		}
+		for _, v := range badRequest.GetFieldViolations() {
+			violations = append(violations, sunerror.FieldViolation{Field: v.GetField(), Message: v.GetDescription()})
+		}
+	}
+	return violations
+}
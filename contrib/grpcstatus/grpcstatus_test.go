@@ -0,0 +1,81 @@
+package grpcstatus
+
+import (
+	"context"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sjmshsh/sunerror"
+)
+
+func FuzzStatusRoundTrip(f *testing.F) {
+	f.Add("ORDER_1001", "404", "订单不存在", "orderID=1, user=2", "", "", "", "", "", uint8(0), int8(0), false, false, false, int64(0))
+	f.Add("", "", "", "", "", "", "", "", "", uint8(0), int8(0), false, false, false, int64(0))
+	f.Add("PAY_9", "502", "下游 失败 😀", "line1\nline2\t\"q\"", "E100", "timeout=3s, retry", "请稍后重试", "https://docs/x", "id-1", uint8(6), int8(2), true, true, true, int64(1500))
+	f.Fuzz(func(t *testing.T, code, status, msg, detail, channelCode, channelMsg, userMsg, docsURL, errorID string,
+		kind uint8, level int8, retryable, sideEffect, violated bool, retryAfter int64) {
+		for _, s := range []string{code, status, msg, detail, channelCode, channelMsg, userMsg, docsURL, errorID} {
+			// protobuf的string字段要求合法UTF-8
+			if !utf8.ValidString(s) {
+				t.Skip()
+			}
+		}
+		ctx := context.Background()
+		opts := []sunerror.SunErrOption{
+			sunerror.WithNoLogOption(),
+			sunerror.WithStackOption(false),
+			sunerror.WithDetailOption("%s", detail),
+			sunerror.WithChannelRespOption(channelCode, channelMsg),
+			sunerror.WithUserMsgOption(userMsg),
+			sunerror.WithDocsURLOption(docsURL),
+			sunerror.WithKindOption(sunerror.SunErrKind(kind % uint8(sunerror.InternalKind+1))),
+			sunerror.WithRetryableOption(retryable),
+			sunerror.WithSideEffectOption(sideEffect),
+			sunerror.WithLogLevelOption(sunerror.SunErrLevel(int(uint8(level))%int(sunerror.FatalLevel+1) - 1)),
+		}
+		if len(errorID) > 0 {
+			opts = append(opts, sunerror.WithErrorIDOption(errorID))
+		}
+		if violated {
+			// BadRequest不携带规则名, Rule留空才能原样还原
+			opts = append(opts, sunerror.WithViolationsOption(sunerror.FieldViolation{Field: code, Message: msg}))
+		}
+		if retryAfter > 0 {
+			opts = append(opts, sunerror.WithRetryAfterOption(time.Duration(retryAfter)))
+		}
+		orig := sunerror.NewSunError(ctx, sunerror.Code(code), status, msg, opts...)
+		decoded := FromStatus(ctx, ToStatus(orig), sunerror.WithFuncNameOption(orig.GetFnName()))
+		if decoded == nil {
+			t.Fatalf("FromStatus returned nil for %v", orig)
+		}
+		// 远端的fnName单独保存; traceID不随status传递, 其余字段应原样还原
+		if remoteFn, _, ok := decoded.GetRemote(); !ok || remoteFn != orig.GetFnName() {
+			t.Fatalf("remote fnName = %q, want %q", remoteFn, orig.GetFnName())
+		}
+		origAfter, origHas := orig.GetRetryAfter()
+		decodedAfter, decodedHas := decoded.GetRetryAfter()
+		if decoded.GetCode() != orig.GetCode() ||
+			decoded.GetStatus() != orig.GetStatus() ||
+			decoded.GetMsg() != orig.GetMsg() ||
+			decoded.GetDetail() != orig.GetDetail() ||
+			decoded.GetChannelCode() != orig.GetChannelCode() ||
+			decoded.GetChannelMsg() != orig.GetChannelMsg() ||
+			decoded.GetErrorID() != orig.GetErrorID() ||
+			decoded.GetUserMsg() != orig.GetUserMsg() ||
+			decoded.GetDocsURL() != orig.GetDocsURL() ||
+			decoded.GetKind() != orig.GetKind() ||
+			decoded.GetLevel() != orig.GetLevel() ||
+			decoded.IsRetryable() != orig.IsRetryable() ||
+			decoded.HasSideEffect() != orig.HasSideEffect() ||
+			decodedAfter != origAfter || decodedHas != origHas ||
+			len(decoded.GetViolations()) != len(orig.GetViolations()) {
+			t.Fatalf("gRPC status round trip lost fields\norig:    %+v\ndecoded: %+v", orig.ToRecord(false), decoded.ToRecord(false))
+		}
+		for i, v := range orig.GetViolations() {
+			if decoded.GetViolations()[i] != v {
+				t.Fatalf("violation %d = %+v, want %+v", i, decoded.GetViolations()[i], v)
+			}
+		}
+	})
+}
@@ -0,0 +1,5 @@
+module github.com/sjmshsh/sunerror/contrib/kitex
+
+go 1.22
+
+replace github.com/sjmshsh/sunerror => ../..
@@ -0,0 +1,93 @@
+// Package kitex 提供Kitex的服务端/客户端中间件, 通过业务异常(BizStatusError)传递SunError
+// 服务端与客户端需要启用TTHeader或gRPC传输的元信息处理, 业务异常才能透传
+package kitex
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/cloudwego/kitex/pkg/endpoint"
+	"github.com/cloudwego/kitex/pkg/kerrors"
+	"github.com/sjmshsh/sunerror"
+)
+
+// BizExtra中使用的key
+const (
+	extraCode        = "code"
+	extraStatus      = "status"
+	extraDetail      = "detail"
+	extraFnName      = "fnName"
+	extraChannelCode = "channelCode"
+	extraChannelMsg  = "channelMsg"
+	extraErrorID     = "errorID"
+)
+
+// CodeMapper 将SunError的字符串错误码映射为BizStatusCode, 默认解析数字错误码, 无法解析时返回-1
+var CodeMapper = func(e *sunerror.SunError) int32 {
+	code, err := strconv.ParseInt(e.GetCode().String(), 10, 32)
+	if err != nil {
+		return -1
+	}
+	return int32(code)
+}
+
+// ServerMiddleware 服务端中间件, 通过server.WithMiddleware注册, 将handler返回的SunError转换为业务异常
+func ServerMiddleware(next endpoint.Endpoint) endpoint.Endpoint {
+	return func(ctx context.Context, req, resp interface{}) error {
+		err := next(ctx, req, resp)
+		var sunErr *sunerror.SunError
+		if !errors.As(err, &sunErr) {
+			return err
+		}
+		return kerrors.NewBizStatusErrorWithExtra(CodeMapper(sunErr), sunErr.GetMsg(), map[string]string{
+			extraCode:        sunErr.GetCode().String(),
+			extraStatus:      sunErr.GetStatus(),
+			extraDetail:      sunErr.GetDetail(),
+			extraFnName:      sunErr.GetFnName(),
+			extraChannelCode: sunErr.GetChannelCode(),
+			extraChannelMsg:  sunErr.GetChannelMsg(),
+			extraErrorID:     sunErr.GetErrorID(),
+		})
+	}
+}
+
+// ClientMiddleware 客户端中间件, 通过client.WithMiddleware注册, 将业务异常还原为SunError
+func ClientMiddleware(next endpoint.Endpoint) endpoint.Endpoint {
+	return func(ctx context.Context, req, resp interface{}) error {
+		err := next(ctx, req, resp)
+		if sunErr := FromBizStatusError(ctx, err); sunErr != nil {
+			return sunErr
+		}
+		return err
+	}
+}
+
+// FromBizStatusError 从Kitex业务异常还原SunError, 不是业务异常时返回nil
+// 对端不是本包的服务端中间件时, 以BizStatusCode作为code与status; 还原时不打印日志也不保存本地堆栈
+func FromBizStatusError(ctx context.Context, err error, opts ...sunerror.SunErrOption) *sunerror.SunError {
+	bizErr, ok := kerrors.FromBizStatusError(err)
+	if !ok {
+		return nil
+	}
+	extra := bizErr.BizExtra()
+	code, ok := extra[extraCode]
+	if !ok {
+		code = strconv.Itoa(int(bizErr.BizStatusCode()))
+	}
+	status, ok := extra[extraStatus]
+	if !ok {
+		status = code
+	}
+	fields := []sunerror.SunErrOption{
+		sunerror.WithNoLogOption(),
+		sunerror.WithStackOption(false),
+		sunerror.WithRestoredOption(),
+		sunerror.WithSkipDepthOption(1),
+		sunerror.WithRemoteOption(extra[extraFnName], ""),
+		sunerror.WithDetailOption("%s", extra[extraDetail]),
+		sunerror.WithChannelRespOption(extra[extraChannelCode], extra[extraChannelMsg]),
+		sunerror.WithErrorIDOption(extra[extraErrorID]),
+	}
+	return sunerror.NewSunError(ctx, sunerror.Code(code), status, bizErr.BizMessage(), append(fields, opts...)...)
+}
@@ -0,0 +1,64 @@
+// Package kratos 提供SunError与kratos errors.Error之间的双向转换
+// 错误码写入Reason, status/detail/下游信息等写入Metadata
+package kratos
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/sjmshsh/sunerror"
+)
+
+// Metadata中使用的key
+const (
+	metaStatus      = "status"
+	metaDetail      = "detail"
+	metaFnName      = "fnName"
+	metaChannelCode = "channelCode"
+	metaChannelMsg  = "channelMsg"
+	metaErrorID     = "errorID"
+)
+
+// CodeMapper 决定SunError对应的kratos Code(HTTP状态码), 默认为SunError.HTTPStatus()
+var CodeMapper = func(e *sunerror.SunError) int {
+	return e.HTTPStatus()
+}
+
+// ToKratos 将SunError转换为kratos errors.Error, 合规模式下不包含fnName/detail/下游信息
+func ToKratos(e *sunerror.SunError) *errors.Error {
+	e = e.External()
+	return errors.New(CodeMapper(e), e.GetCode().String(), e.GetMsg()).WithMetadata(map[string]string{
+		metaStatus:      e.GetStatus(),
+		metaDetail:      e.GetDetail(),
+		metaFnName:      e.GetFnName(),
+		metaChannelCode: e.GetChannelCode(),
+		metaChannelMsg:  e.GetChannelMsg(),
+		metaErrorID:     e.GetErrorID(),
+	})
+}
+
+// FromKratos 将kratos error还原为SunError, err为nil时返回nil
+// Metadata中没有status时使用kratos Code作为status; 还原时不打印日志也不保存本地堆栈
+func FromKratos(ctx context.Context, err error, opts ...sunerror.SunErrOption) *sunerror.SunError {
+	ke := errors.FromError(err)
+	if ke == nil {
+		return nil
+	}
+	md := ke.Metadata
+	status, ok := md[metaStatus]
+	if !ok {
+		status = strconv.Itoa(int(ke.Code))
+	}
+	fields := []sunerror.SunErrOption{
+		sunerror.WithNoLogOption(),
+		sunerror.WithStackOption(false),
+		sunerror.WithRestoredOption(),
+		sunerror.WithSkipDepthOption(1),
+		sunerror.WithRemoteOption(md[metaFnName], ""),
+		sunerror.WithDetailOption("%s", md[metaDetail]),
+		sunerror.WithChannelRespOption(md[metaChannelCode], md[metaChannelMsg]),
+		sunerror.WithErrorIDOption(md[metaErrorID]),
+	}
+	return sunerror.NewSunError(ctx, sunerror.Code(ke.Reason), status, ke.Message, append(fields, opts...)...)
+}
@@ -0,0 +1,55 @@
+// Package mongo 为sunerror.FromError识别mongo-go-driver的错误
+package mongo
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/sjmshsh/sunerror"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Name 注册分类器使用的名称
+const Name = "mongo"
+
+// 识别结果使用的错误码
+const (
+	ErrorCode        sunerror.Code = "MONGO_ERROR"
+	NotFoundCode     sunerror.Code = "MONGO_NOT_FOUND"
+	DuplicateKeyCode sunerror.Code = "MONGO_DUPLICATE_KEY"
+)
+
+// Register 注册mongo错误分类器, 在初始化时调用一次
+func Register() {
+	sunerror.RegisterErrorClassifier(Name, Classify)
+}
+
+// Classify sunerror.ErrorClassifier实现
+// 1. mongo.ErrNoDocuments为NotFoundCode, 唯一索引冲突为DuplicateKeyCode
+// 2. 超时为可重试的TimeoutKind, 网络错误与带TransientTransactionError/RetryableWriteError标签的错误为可重试的TransientKind
+// 3. 服务端命令错误记录错误号与错误名为下游错误码/信息
+func Classify(err error) (sunerror.SDKError, bool) {
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return sunerror.SDKError{Code: NotFoundCode, Msg: "document not found", Kind: sunerror.NotFoundKind}, true
+	case mongo.IsDuplicateKeyError(err):
+		return sunerror.SDKError{Code: DuplicateKeyCode, Msg: "duplicate key", Kind: sunerror.ConflictKind}, true
+	case mongo.IsTimeout(err):
+		return sunerror.SDKError{Code: ErrorCode, Kind: sunerror.TimeoutKind, Retryable: true}, true
+	case mongo.IsNetworkError(err):
+		return sunerror.SDKError{Code: ErrorCode, Kind: sunerror.TransientKind, Retryable: true}, true
+	}
+	var serverErr mongo.ServerError
+	if !errors.As(err, &serverErr) {
+		return sunerror.SDKError{}, false
+	}
+	info := sunerror.SDKError{Code: ErrorCode, Kind: sunerror.DownstreamKind}
+	if serverErr.HasErrorLabel("TransientTransactionError") || serverErr.HasErrorLabel("RetryableWriteError") {
+		info.Kind, info.Retryable = sunerror.TransientKind, true
+	}
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) {
+		info.ChannelCode, info.ChannelMsg = strconv.Itoa(int(cmdErr.Code)), cmdErr.Name
+	}
+	return info, true
+}
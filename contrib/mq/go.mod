@@ -0,0 +1,7 @@
+module github.com/sjmshsh/sunerror/contrib/mq
+
+go 1.22.0
+
+replace github.com/sjmshsh/sunerror => ../..
+
+require github.com/sjmshsh/sunerror v0.0.0-00010101000000-000000000000
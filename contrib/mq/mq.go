@@ -0,0 +1,150 @@
+// Package mq 统一MQ消费端的错误处理策略: 按SunError的retryable与kind决定重试或进入死信, 并上报指标
+// 与具体MQ客户端无关, Kafka等消费者在处理每条消息时调用Handle即可, asynq见contrib/asynq
+package mq
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sjmshsh/sunerror"
+)
+
+// Decision 消息处理结果的处置方式
+type Decision int8
+
+const (
+	// Ack 处理成功, 提交消息
+	Ack Decision = iota
+	// Retry 稍后重试
+	Retry
+	// DeadLetter 不再重试, 投递到死信队列
+	DeadLetter
+)
+
+func (d Decision) String() string {
+	switch d {
+	case Ack:
+		return "ack"
+	case Retry:
+		return "retry"
+	case DeadLetter:
+		return "dead_letter"
+	}
+	return "unknown"
+}
+
+// Classifier 根据处理返回的错误与当前尝试次数(从1开始)决定处置方式
+type Classifier func(err error, attempt, maxAttempts int) Decision
+
+// Metrics 消费指标上报, code为空表示成功或非SunError
+type Metrics interface {
+	IncConsume(topic, code string, decision Decision)
+}
+
+type handleConfig struct {
+	maxAttempts int
+	classify    Classifier
+	metrics     Metrics
+}
+
+// Option Handle的配置函数
+type Option func(c *handleConfig)
+
+// WithMaxAttempts 设置最大尝试次数, 默认3次
+func WithMaxAttempts(maxAttempts int) Option {
+	return func(c *handleConfig) {
+		if maxAttempts > 0 {
+			c.maxAttempts = maxAttempts
+		}
+	}
+}
+
+// WithClassifier 自定义处置策略, 默认为DefaultClassifier
+func WithClassifier(classify Classifier) Option {
+	return func(c *handleConfig) {
+		if classify != nil {
+			c.classify = classify
+		}
+	}
+}
+
+// WithMetrics 设置指标上报
+func WithMetrics(metrics Metrics) Option {
+	return func(c *handleConfig) {
+		c.metrics = metrics
+	}
+}
+
+// DefaultClassifier 默认处置策略
+// 1. 可重试的SunError, 或kind为Transient/Timeout/Downstream时重试
+// 2. 其他SunError(如参数校验失败)及可能已产生副作用的SunError直接进入死信
+// 3. 非SunError视为未知错误, 重试
+// 达到最大尝试次数后一律进入死信
+func DefaultClassifier(err error, attempt, maxAttempts int) Decision {
+	if err == nil {
+		return Ack
+	}
+	var sunErr *sunerror.SunError
+	if errors.As(err, &sunErr) && !isTransient(sunErr) {
+		return DeadLetter
+	}
+	if attempt >= maxAttempts {
+		return DeadLetter
+	}
+	return Retry
+}
+
+func isTransient(e *sunerror.SunError) bool {
+	if e.HasSideEffect() {
+		return false
+	}
+	if e.IsRetryable() {
+		return true
+	}
+	switch e.GetKind() {
+	case sunerror.TransientKind, sunerror.TimeoutKind, sunerror.DownstreamKind:
+		return true
+	}
+	return false
+}
+
+// Handle 执行消息处理函数并给出处置方式, attempt为当前尝试次数(从1开始)
+// 返回的SunError会在detail中追加topic与尝试次数; SunError被其他错误包装时保留外层的包装, 见annotatedError
+func Handle(ctx context.Context, topic string, attempt int, fn func(ctx context.Context) error, opts ...Option) (Decision, error) {
+	c := &handleConfig{maxAttempts: 3, classify: DefaultClassifier}
+	for _, opt := range opts {
+		opt(c)
+	}
+	err := fn(ctx)
+	decision := c.classify(err, attempt, c.maxAttempts)
+
+	var code string
+	var sunErr *sunerror.SunError
+	if errors.As(err, &sunErr) {
+		code = sunErr.GetCode().String()
+		annotated := sunErr.AppendDetail("topic=%s attempt=%d/%d decision=%s", topic, attempt, c.maxAttempts, decision)
+		if err == error(sunErr) {
+			err = annotated
+		} else {
+			err = &annotatedError{err: err, sunErr: annotated}
+		}
+	}
+	if c.metrics != nil {
+		c.metrics.IncConsume(topic, code, decision)
+	}
+	return decision, err
+}
+
+// annotatedError 保留外层包装的错误, Error()与errors.Is按原错误, errors.As(*SunError)优先得到追加了detail的SunError
+type annotatedError struct {
+	err    error
+	sunErr *sunerror.SunError
+}
+
+func (e *annotatedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *annotatedError) Unwrap() []error {
+	return []error{e.sunErr, e.err}
+}
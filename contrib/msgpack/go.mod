@@ -0,0 +1,12 @@
+module github.com/sjmshsh/sunerror/contrib/msgpack
+
+go 1.22.0
+
+replace github.com/sjmshsh/sunerror => ../..
+
+require (
+	github.com/sjmshsh/sunerror v0.0.0-00010101000000-000000000000
+	github.com/vmihailenco/msgpack/v5 v5.4.1
+)
+
+require github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
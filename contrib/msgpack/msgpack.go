@@ -0,0 +1,51 @@
+// Package msgpack 以MessagePack编码SunError, 字段与MarshalJSON一致(沿用sunerror.Record的json标签), 体积更小, 适合二进制MQ负载
+package msgpack
+
+import (
+	"bytes"
+
+	"github.com/sjmshsh/sunerror"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Marshal 编码全部可跨进程传递的字段, withStack为false时不包含堆栈
+func Marshal(e *sunerror.SunError, withStack bool) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	enc.SetOmitEmpty(true)
+	if err := enc.Encode(e.ToRecord(withStack)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal 从Marshal的结果还原SunError, 还原时不打印日志也不执行执行器
+func Unmarshal(data []byte) (*sunerror.SunError, error) {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	var r sunerror.Record
+	if err := dec.Decode(&r); err != nil {
+		return nil, err
+	}
+	return sunerror.FromRecord(r)
+}
+
+// Format 注册到sunerror的序列化格式名
+const Format = "msgpack"
+
+// Register 以Format注册为sunerror.Serializer(包含堆栈), 在初始化时调用一次
+func Register() {
+	sunerror.RegisterSerializer(Format, Serializer{})
+}
+
+// Serializer sunerror.Serializer实现
+type Serializer struct{}
+
+func (Serializer) Encode(e *sunerror.SunError) ([]byte, error) {
+	return Marshal(e, true)
+}
+
+func (Serializer) Decode(data []byte) (*sunerror.SunError, error) {
+	return Unmarshal(data)
+}
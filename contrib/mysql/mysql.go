@@ -0,0 +1,45 @@
+// Package mysql 为sunerror.FromDBError识别go-sql-driver/mysql的错误号
+package mysql
+
+import (
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/sjmshsh/sunerror"
+)
+
+// Numbers MySQL服务端错误号到数据库错误分类的映射, 各服务共用这一份, 新增映射时在这里维护
+var Numbers = map[uint16]sunerror.DBErrorClass{
+	1022: sunerror.DBDuplicateKey,  // ER_DUP_KEY
+	1062: sunerror.DBDuplicateKey,  // ER_DUP_ENTRY
+	1586: sunerror.DBDuplicateKey,  // ER_DUP_ENTRY_WITH_KEY_NAME
+	1213: sunerror.DBDeadlock,      // ER_LOCK_DEADLOCK
+	1614: sunerror.DBDeadlock,      // ER_XA_RBDEADLOCK
+	1205: sunerror.DBLockTimeout,   // ER_LOCK_WAIT_TIMEOUT
+	3572: sunerror.DBLockTimeout,   // ER_LOCK_NOWAIT
+	1040: sunerror.DBConnection,    // ER_CON_COUNT_ERROR
+	1053: sunerror.DBConnection,    // ER_SERVER_SHUTDOWN
+	1158: sunerror.DBConnection,    // ER_NET_READ_ERROR
+	1159: sunerror.DBConnection,    // ER_NET_READ_INTERRUPTED
+	1160: sunerror.DBConnection,    // ER_NET_ERROR_ON_WRITE
+	1161: sunerror.DBConnection,    // ER_NET_WRITE_INTERRUPTED
+	3101: sunerror.DBSerialization, // ER_TRANSACTION_ROLLBACK_DURING_COMMIT
+}
+
+// Register 注册MySQL错误分类器, 在初始化时调用一次
+func Register() {
+	sunerror.RegisterDBClassifier(Classify)
+}
+
+// Classify sunerror.DBClassifier实现, 服务端错误按Numbers识别, 驱动的mysql.ErrInvalidConn为连接错误
+func Classify(err error) (sunerror.DBErrorClass, bool) {
+	if errors.Is(err, mysql.ErrInvalidConn) {
+		return sunerror.DBConnection, true
+	}
+	var myErr *mysql.MySQLError
+	if !errors.As(err, &myErr) {
+		return sunerror.DBOther, false
+	}
+	class, ok := Numbers[myErr.Number]
+	return class, ok
+}
@@ -0,0 +1,15 @@
+module github.com/sjmshsh/sunerror/contrib/oss
+
+go 1.26.0
+
+replace github.com/sjmshsh/sunerror => ../..
+
+require (
+	github.com/aliyun/aliyun-oss-go-sdk v3.0.2+incompatible
+	github.com/sjmshsh/sunerror v0.0.0-00010101000000-000000000000
+)
+
+require (
+	golang.org/x/time v0.16.0 // indirect
+	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
+)
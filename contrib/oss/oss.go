@@ -0,0 +1,61 @@
+// Package oss 为sunerror.FromError识别阿里云OSS SDK的错误
+package oss
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+	"github.com/sjmshsh/sunerror"
+)
+
+// Name 注册分类器使用的名称
+const Name = "oss"
+
+// 识别结果使用的错误码
+const (
+	ErrorCode        sunerror.Code = "OSS_ERROR"
+	NotFoundCode     sunerror.Code = "OSS_NOT_FOUND"
+	AccessDeniedCode sunerror.Code = "OSS_ACCESS_DENIED"
+)
+
+// Register 注册OSS错误分类器, 在初始化时调用一次
+func Register() {
+	sunerror.RegisterErrorClassifier(Name, Classify)
+}
+
+// Classify sunerror.ErrorClassifier实现, 按OSS错误码识别, 未知错误码按HTTP状态码识别(5xx与429可重试)
+// 原始错误码与错误信息记录为下游错误码/信息
+func Classify(err error) (sunerror.SDKError, bool) {
+	var svcErr oss.ServiceError
+	if !errors.As(err, &svcErr) {
+		var svcErrPtr *oss.ServiceError
+		if !errors.As(err, &svcErrPtr) {
+			return sunerror.SDKError{}, false
+		}
+		svcErr = *svcErrPtr
+	}
+	info := sunerror.SDKError{Code: ErrorCode, Kind: sunerror.DownstreamKind, ChannelCode: svcErr.Code, ChannelMsg: svcErr.Message}
+	switch svcErr.Code {
+	case "NoSuchKey", "NoSuchBucket", "NoSuchUpload":
+		info.Code, info.Kind = NotFoundCode, sunerror.NotFoundKind
+	case "AccessDenied":
+		info.Code, info.Kind = AccessDeniedCode, sunerror.PermissionDeniedKind
+	case "InvalidAccessKeyId", "SignatureDoesNotMatch", "SecurityTokenExpired":
+		info.Kind = sunerror.UnauthenticatedKind
+	case "BucketAlreadyExists", "PositionNotEqualToLength":
+		info.Kind = sunerror.ConflictKind
+	case "RequestTimeout":
+		info.Kind, info.Retryable = sunerror.TimeoutKind, true
+	default:
+		switch {
+		case svcErr.StatusCode == http.StatusTooManyRequests:
+			info.Kind, info.Retryable = sunerror.TransientKind, true
+		case svcErr.StatusCode >= http.StatusInternalServerError:
+			info.Retryable = true
+		case svcErr.StatusCode >= http.StatusBadRequest:
+			info.Kind = sunerror.ValidationKind
+		}
+	}
+	return info, true
+}
@@ -0,0 +1,53 @@
+// Package postgres 为sunerror.FromDBError识别PostgreSQL的SQLSTATE, 适用于实现了SQLState() string的驱动(pgx、lib/pq)
+// sunerror内置的分类器只识别最常见的几个SQLSTATE, 注册本包后按SQLStates与SQLStateClasses的完整映射识别
+package postgres
+
+import (
+	"errors"
+
+	"github.com/sjmshsh/sunerror"
+)
+
+// SQLStates SQLSTATE到数据库错误分类的映射, 各服务共用这一份, 新增映射时在这里维护
+var SQLStates = map[string]sunerror.DBErrorClass{
+	"23505": sunerror.DBDuplicateKey,  // unique_violation
+	"40P01": sunerror.DBDeadlock,      // deadlock_detected
+	"40001": sunerror.DBSerialization, // serialization_failure
+	"55P03": sunerror.DBLockTimeout,   // lock_not_available
+	"53300": sunerror.DBConnection,    // too_many_connections
+	"57P01": sunerror.DBConnection,    // admin_shutdown
+	"57P02": sunerror.DBConnection,    // crash_shutdown
+	"57P03": sunerror.DBConnection,    // cannot_connect_now
+}
+
+// SQLStateClasses SQLSTATE前两位(错误类别)到数据库错误分类的映射, SQLStates中没有的SQLSTATE按类别识别
+var SQLStateClasses = map[string]sunerror.DBErrorClass{
+	"08": sunerror.DBConnection, // connection_exception
+}
+
+type sqlStater interface {
+	SQLState() string
+}
+
+// Register 注册PostgreSQL错误分类器, 在初始化时调用一次
+func Register() {
+	sunerror.RegisterDBClassifier(Classify)
+}
+
+// Classify sunerror.DBClassifier实现, 先按SQLStates, 再按SQLStateClasses识别
+func Classify(err error) (sunerror.DBErrorClass, bool) {
+	var s sqlStater
+	if !errors.As(err, &s) {
+		return sunerror.DBOther, false
+	}
+	state := s.SQLState()
+	if class, ok := SQLStates[state]; ok {
+		return class, true
+	}
+	if len(state) == 5 {
+		if class, ok := SQLStateClasses[state[:2]]; ok {
+			return class, true
+		}
+	}
+	return sunerror.DBOther, false
+}
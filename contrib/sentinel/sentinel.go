@@ -0,0 +1,17 @@
+// Package sentinel 让sentinel-go的熔断规则只统计服务端/下游类的SunError
+package sentinel
+
+import (
+	"github.com/alibaba/sentinel-golang/api"
+	"github.com/alibaba/sentinel-golang/core/base"
+	"github.com/sjmshsh/sunerror"
+)
+
+// TraceError 按sunerror.IsBreakerFailure判定后再调用api.TraceError记录错误
+// 参数校验等调用方错误不会计入错误数/错误率熔断
+func TraceError(entry *base.SentinelEntry, err error, opts ...api.TraceErrorOption) {
+	if entry == nil || !sunerror.IsBreakerFailure(err) {
+		return
+	}
+	api.TraceError(entry, err, opts...)
+}
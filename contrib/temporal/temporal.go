@@ -0,0 +1,83 @@
+// Package temporal 提供SunError与Temporal ApplicationError之间的双向转换
+// 错误码作为ApplicationError的Type, 完整字段通过details payload传递
+package temporal
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sjmshsh/sunerror"
+	"go.temporal.io/sdk/temporal"
+)
+
+// Payload ApplicationError中携带的SunError字段
+type Payload struct {
+	Code        string              `json:"code"`
+	Status      string              `json:"status"`
+	Msg         string              `json:"msg"`
+	Detail      string              `json:"detail,omitempty"`
+	FnName      string              `json:"fnName,omitempty"`
+	ChannelCode string              `json:"channelCode,omitempty"`
+	ChannelMsg  string              `json:"channelMsg,omitempty"`
+	ErrorID     string              `json:"errorID,omitempty"`
+	Kind        sunerror.SunErrKind `json:"kind,omitempty"`
+	Retryable   bool                `json:"retryable,omitempty"`
+}
+
+// NonRetryable 决定ApplicationError是否标记为不可重试
+// 默认参数校验/认证/权限/不存在/冲突等调用方错误不可重试, 其余交给Temporal的RetryPolicy
+var NonRetryable = func(e *sunerror.SunError) bool {
+	switch e.GetKind() {
+	case sunerror.ValidationKind, sunerror.UnauthenticatedKind, sunerror.PermissionDeniedKind,
+		sunerror.NotFoundKind, sunerror.ConflictKind:
+		return !e.IsRetryable()
+	}
+	return false
+}
+
+// ToApplicationError 将SunError转换为Temporal ApplicationError, 在activity/workflow中返回
+func ToApplicationError(e *sunerror.SunError) error {
+	payload := Payload{
+		Code:        e.GetCode().String(),
+		Status:      e.GetStatus(),
+		Msg:         e.GetMsg(),
+		Detail:      e.GetDetail(),
+		FnName:      e.GetFnName(),
+		ChannelCode: e.GetChannelCode(),
+		ChannelMsg:  e.GetChannelMsg(),
+		ErrorID:     e.GetErrorID(),
+		Kind:        e.GetKind(),
+		Retryable:   e.IsRetryable(),
+	}
+	return temporal.NewApplicationErrorWithOptions(e.GetMsg(), e.GetCode().String(), temporal.ApplicationErrorOptions{
+		NonRetryable: NonRetryable(e),
+		Details:      []interface{}{payload},
+	})
+}
+
+// FromApplicationError 从err链(如ActivityError/WorkflowExecutionError)中的ApplicationError还原SunError
+// 不存在ApplicationError时返回nil; 没有payload时以Type作为code, Message作为msg
+// 还原时不打印日志也不保存本地堆栈
+func FromApplicationError(ctx context.Context, err error, opts ...sunerror.SunErrOption) *sunerror.SunError {
+	var appErr *temporal.ApplicationError
+	if !errors.As(err, &appErr) {
+		return nil
+	}
+	payload := Payload{Code: appErr.Type(), Msg: appErr.Message()}
+	if appErr.HasDetails() {
+		_ = appErr.Details(&payload)
+	}
+	fields := []sunerror.SunErrOption{
+		sunerror.WithNoLogOption(),
+		sunerror.WithStackOption(false),
+		sunerror.WithRestoredOption(),
+		sunerror.WithSkipDepthOption(1),
+		sunerror.WithRemoteOption(payload.FnName, ""),
+		sunerror.WithDetailOption("%s", payload.Detail),
+		sunerror.WithChannelRespOption(payload.ChannelCode, payload.ChannelMsg),
+		sunerror.WithErrorIDOption(payload.ErrorID),
+		sunerror.WithKindOption(payload.Kind),
+		sunerror.WithRetryableOption(payload.Retryable && !appErr.NonRetryable()),
+	}
+	return sunerror.NewSunError(ctx, sunerror.Code(payload.Code), payload.Status, payload.Msg, append(fields, opts...)...)
+}
@@ -0,0 +1,191 @@
+package sunerror
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// FatalHandler FatalLevel错误的处理函数, 在打印日志与同步执行器之后、异步执行器之前调用
+type FatalHandler func(e *SunError)
+
+// ExitOnFatal 以ExitCode(e)退出进程
+func ExitOnFatal(e *SunError) {
+	osExit(ExitCode(e))
+}
+
+// PanicOnFatal 以错误本身panic, 交给上层的recover处理
+func PanicOnFatal(e *SunError) {
+	panic(e)
+}
+
+var (
+	// crashReportDir 崩溃报告目录, 为空时不开启
+	crashReportDir atomic.Value
+	// fatalHandler FatalLevel错误的处理函数, 为nil时只打印日志
+	fatalHandler atomic.Value
+)
+
+// SetFatalHandler 设置FatalLevel错误的处理, 可以是ExitOnFatal、PanicOnFatal或自定义回调; 传nil时只打印日志(默认)
+func SetFatalHandler(h FatalHandler) {
+	fatalHandler.Store(h)
+}
+
+// SetCrashReportDir 开启FatalLevel错误的崩溃报告, dir为空时关闭(默认关闭)
+// 开启后构造FatalLevel错误时, 先将完整的崩溃报告写入dir, 再执行SetFatalHandler设置的处理, 未设置时以ExitCode(err)退出进程
+// 报告包含错误记录(含堆栈)、全部协程的调用栈、构建信息、运行时与环境变量摘要, 类似JVM的hs_err文件
+func SetCrashReportDir(dir string) {
+	crashReportDir.Store(dir)
+}
+
+func getCrashReportDir() string {
+	dir, _ := crashReportDir.Load().(string)
+	return dir
+}
+
+// fatal FatalLevel错误的处理, 未开启崩溃报告也未设置处理函数时只打印日志
+func (e *SunError) fatal() {
+	h, _ := fatalHandler.Load().(FatalHandler)
+	if dir := getCrashReportDir(); len(dir) > 0 {
+		if path, err := WriteCrashReport(dir, e); err != nil {
+			fmt.Fprintf(exitOutput, "sunerror: write crash report failed: %v\n", err)
+		} else {
+			fmt.Fprintf(exitOutput, "sunerror: crash report written to %s\n", path)
+		}
+		if h == nil {
+			h = ExitOnFatal
+		}
+	}
+	if h != nil {
+		h(e)
+	}
+}
+
+// WriteCrashReport 将e的崩溃报告写入dir下的新文件, 返回文件路径; 可用于在recover或信号处理中手动生成报告
+func WriteCrashReport(dir string, e *SunError) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	now := time.Now()
+	name := fmt.Sprintf("sunerror-crash-%s-%d.log", now.Format("20060102-150405"), os.Getpid())
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, crashReport(e, now), 0o600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// crashReport 生成崩溃报告的内容
+func crashReport(e *SunError, now time.Time) []byte {
+	var b bytes.Buffer
+	b.WriteString("=== sunerror crash report ===\n")
+	fmt.Fprintf(&b, "time: %s\n", now.Format(time.RFC3339Nano))
+	fmt.Fprintf(&b, "pid: %d\n", os.Getpid())
+	if exe, err := os.Executable(); err == nil {
+		fmt.Fprintf(&b, "executable: %s\n", exe)
+	}
+	fmt.Fprintf(&b, "args: %q\n", os.Args)
+	if host, err := os.Hostname(); err == nil {
+		fmt.Fprintf(&b, "hostname: %s\n", host)
+	}
+
+	b.WriteString("\n=== error ===\n")
+	b.WriteString(e.Error())
+	b.WriteString("\n\n")
+	if record, err := json.MarshalIndent(e.ToRecord(false), "", "  "); err == nil {
+		b.Write(record)
+		b.WriteByte('\n')
+	}
+
+	if req, resp := e.GetPayload(); len(req) > 0 || len(resp) > 0 {
+		b.WriteString("\n=== payload ===\n")
+		fmt.Fprintf(&b, "request: %s\nresponse: %s\n", req, resp)
+	}
+
+	b.WriteString("\n=== runtime ===\n")
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	fmt.Fprintf(&b, "go: %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "cpus: %d, gomaxprocs: %d, goroutines: %d\n", runtime.NumCPU(), runtime.GOMAXPROCS(0), runtime.NumGoroutine())
+	fmt.Fprintf(&b, "heapAlloc: %d, sys: %d, numGC: %d\n", mem.HeapAlloc, mem.Sys, mem.NumGC)
+
+	b.WriteString("\n=== build info ===\n")
+	if info, ok := debug.ReadBuildInfo(); ok {
+		b.WriteString(info.String())
+	}
+
+	b.WriteString("\n=== environment ===\n")
+	writeEnvSummary(&b)
+
+	b.WriteString("\n=== goroutines ===\n")
+	b.Write(allGoroutines())
+	return b.Bytes()
+}
+
+// sensitiveEnv 名称中包含这些片段的环境变量只输出名称
+var sensitiveEnv = []string{"SECRET", "TOKEN", "PASSWORD", "PASSWD", "KEY", "CREDENTIAL", "AUTH", "DSN"}
+
+// writeEnvSummary 按名称排序输出环境变量, 名称疑似敏感的值替换为***, 其余值中URL的user:pass@替换为***@
+func writeEnvSummary(b *bytes.Buffer) {
+	env := os.Environ()
+	sort.Strings(env)
+	for _, kv := range env {
+		name, value, _ := strings.Cut(kv, "=")
+		value = maskURLUserinfo(value)
+		upper := strings.ToUpper(name)
+		for _, s := range sensitiveEnv {
+			if strings.Contains(upper, s) {
+				value = "***"
+				break
+			}
+		}
+		fmt.Fprintf(b, "%s=%s\n", name, value)
+	}
+}
+
+// maskURLUserinfo 将值中每个URL的userinfo替换为***, 如REDIS_NODES=redis://:pass@h1,redis://:pass@h2
+// userinfo以"://"之后、路径/查询/空白之前的最后一个@为界
+func maskURLUserinfo(value string) string {
+	if !strings.Contains(value, "://") {
+		return value
+	}
+	var b strings.Builder
+	for {
+		i := strings.Index(value, "://")
+		if i < 0 {
+			break
+		}
+		b.WriteString(value[:i+3])
+		value = value[i+3:]
+		end := strings.IndexAny(value, "/?# \t")
+		if end < 0 {
+			end = len(value)
+		}
+		if at := strings.LastIndexByte(value[:end], '@'); at >= 0 {
+			b.WriteString("***")
+			value = value[at:]
+		}
+	}
+	b.WriteString(value)
+	return b.String()
+}
+
+// allGoroutines 全部协程的调用栈, 缓冲区不足时加倍, 最大64MB
+func allGoroutines() []byte {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= 64<<20 {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
@@ -0,0 +1,66 @@
+package sunerror
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+type errorSlotKey struct{}
+
+type lastErrorKey struct{}
+
+// errorSlot 请求范围内最近一次的SunError, 由中间件放入ctx, handler中构造的错误会写入其中
+type errorSlot struct {
+	mu  sync.Mutex
+	err *SunError
+}
+
+// ContextWithErrorSlot 返回带有错误槽位的ctx, 由访问日志/响应写入等外层中间件在请求开始时调用
+// 之后以该ctx(或其派生ctx)构造的SunError都会记录到槽位中, 外层可以通过LastErrorFromContext取出最近一次的错误
+func ContextWithErrorSlot(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(errorSlotKey{}).(*errorSlot); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, errorSlotKey{}, &errorSlot{})
+}
+
+// ContextWithError 返回记录了err的ctx, err不是SunError时原样返回ctx
+// ctx中有错误槽位时同时写入槽位, 外层持有的ctx也能取到
+func ContextWithError(ctx context.Context, err error) context.Context {
+	var sunErr *SunError
+	if !errors.As(err, &sunErr) {
+		return ctx
+	}
+	recordError(ctx, sunErr)
+	return context.WithValue(ctx, lastErrorKey{}, sunErr)
+}
+
+// LastErrorFromContext 返回ctx中记录的最近一次的SunError, 没有时返回nil
+// 优先使用ContextWithError直接放入ctx的错误, 其次是错误槽位中的错误
+func LastErrorFromContext(ctx context.Context) *SunError {
+	if ctx == nil {
+		return nil
+	}
+	if sunErr, ok := ctx.Value(lastErrorKey{}).(*SunError); ok {
+		return sunErr
+	}
+	if slot, ok := ctx.Value(errorSlotKey{}).(*errorSlot); ok {
+		slot.mu.Lock()
+		defer slot.mu.Unlock()
+		return slot.err
+	}
+	return nil
+}
+
+// recordError 将错误写入ctx中的错误槽位, 对象池中的错误归还后会被复用, 不记录
+func recordError(ctx context.Context, e *SunError) {
+	if ctx == nil || e.pooled != nil {
+		return
+	}
+	if slot, ok := ctx.Value(errorSlotKey{}).(*errorSlot); ok {
+		slot.mu.Lock()
+		slot.err = e
+		slot.mu.Unlock()
+	}
+}
@@ -0,0 +1,170 @@
+package sunerror
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// DBErrorClass 数据库错误的分类, 由FromDBError映射为SunError
+type DBErrorClass int8
+
+const (
+	// DBOther 无法识别的数据库错误
+	DBOther DBErrorClass = iota
+	// DBNotFound 查询没有结果, 如sql.ErrNoRows
+	DBNotFound
+	// DBDuplicateKey 唯一键冲突
+	DBDuplicateKey
+	// DBDeadlock 死锁
+	DBDeadlock
+	// DBLockTimeout 等待锁超时
+	DBLockTimeout
+	// DBSerialization 可串行化事务冲突
+	DBSerialization
+	// DBConnection 连接失败或连接已断开
+	DBConnection
+)
+
+// FromDBError未注册定义时使用的错误码与status
+const (
+	DBOtherCode         Code = "DB_ERROR"
+	DBNotFoundCode      Code = "DB_NOT_FOUND"
+	DBDuplicateKeyCode  Code = "DB_DUPLICATE_KEY"
+	DBDeadlockCode      Code = "DB_DEADLOCK"
+	DBLockTimeoutCode   Code = "DB_LOCK_TIMEOUT"
+	DBSerializationCode Code = "DB_SERIALIZATION"
+	DBConnectionCode    Code = "DB_CONNECTION"
+	DBErrorStatus            = "FAILED"
+)
+
+type dbClassInfo struct {
+	code      Code
+	msg       string
+	kind      SunErrKind
+	retryable bool
+}
+
+var dbClasses = [...]dbClassInfo{
+	DBOther:         {DBOtherCode, "database error", InternalKind, false},
+	DBNotFound:      {DBNotFoundCode, "record not found", NotFoundKind, false},
+	DBDuplicateKey:  {DBDuplicateKeyCode, "duplicate key", ConflictKind, false},
+	DBDeadlock:      {DBDeadlockCode, "deadlock detected", TransientKind, true},
+	DBLockTimeout:   {DBLockTimeoutCode, "lock wait timeout", TimeoutKind, true},
+	DBSerialization: {DBSerializationCode, "serialization failure", TransientKind, true},
+	DBConnection:    {DBConnectionCode, "database connection error", TransientKind, true},
+}
+
+func (c DBErrorClass) info() dbClassInfo {
+	if c >= 0 && int(c) < len(dbClasses) {
+		return dbClasses[c]
+	}
+	return dbClasses[DBOther]
+}
+
+// DBClassifier 识别特定驱动的错误, ok为false时交给下一个分类器
+type DBClassifier func(err error) (class DBErrorClass, ok bool)
+
+var (
+	dbMu          sync.RWMutex
+	dbClassifiers = []DBClassifier{classifyStdDBError, classifySQLState}
+	dbDefinitions = map[DBErrorClass]*Definition{}
+)
+
+// RegisterDBClassifier 注册驱动的错误分类器(如MySQL错误号), 优先于内置的分类器
+func RegisterDBClassifier(c DBClassifier) {
+	dbMu.Lock()
+	defer dbMu.Unlock()
+	dbClassifiers = append([]DBClassifier{c}, dbClassifiers...)
+}
+
+// RegisterDBDefinition 指定某类数据库错误使用的错误定义, 未注册时使用DBNotFoundCode等默认错误码
+func RegisterDBDefinition(class DBErrorClass, def *Definition) {
+	dbMu.Lock()
+	defer dbMu.Unlock()
+	dbDefinitions[class] = def
+}
+
+// ClassifyDBError 按已注册的分类器识别数据库错误, 都无法识别时返回DBOther
+func ClassifyDBError(err error) DBErrorClass {
+	dbMu.RLock()
+	defer dbMu.RUnlock()
+	for _, c := range dbClassifiers {
+		if class, ok := c(err); ok {
+			return class
+		}
+	}
+	return DBOther
+}
+
+// FromDBError 将数据库错误转换为SunError并包装原始错误, err为nil时返回nil
+// 分类注册了定义时按定义构造, 否则使用该分类默认的错误码、kind与是否可重试(死锁/锁超时/串行化冲突/连接错误可重试)
+// 已经是SunError的err原样返回
+func FromDBError(ctx context.Context, err error, opts ...SunErrOption) *SunError {
+	if err == nil {
+		return nil
+	}
+	var sunErr *SunError
+	if errors.As(err, &sunErr) {
+		return sunErr
+	}
+	class := ClassifyDBError(err)
+	dbMu.RLock()
+	def := dbDefinitions[class]
+	dbMu.RUnlock()
+	if def != nil {
+		fields := []SunErrOption{WithCauseOption(err), WithSkipDepthOption(1)}
+		return def.New(ctx, append(fields, opts...)...)
+	}
+	info := class.info()
+	fields := []SunErrOption{
+		WithCauseOption(err),
+		WithSkipDepthOption(1),
+		WithKindOption(info.kind),
+		WithRetryableOption(info.retryable),
+	}
+	if class == DBNotFound {
+		fields = append(fields, WithLogLevelOption(InfoLevel), WithStackOption(false))
+	}
+	return NewSunError(ctx, info.code, DBErrorStatus, info.msg, append(fields, opts...)...)
+}
+
+func classifyStdDBError(err error) (DBErrorClass, bool) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return DBNotFound, true
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, sql.ErrConnDone):
+		return DBConnection, true
+	}
+	return DBOther, false
+}
+
+// sqlStater pgx(pgconn.PgError)与lib/pq等驱动的错误实现的接口
+type sqlStater interface {
+	SQLState() string
+}
+
+// classifySQLState 按SQLSTATE识别: 23505唯一键冲突, 40P01死锁, 40001串行化冲突, 55P03等锁超时, 08类连接错误
+func classifySQLState(err error) (DBErrorClass, bool) {
+	var s sqlStater
+	if !errors.As(err, &s) {
+		return DBOther, false
+	}
+	state := s.SQLState()
+	switch {
+	case state == "23505":
+		return DBDuplicateKey, true
+	case state == "40P01":
+		return DBDeadlock, true
+	case state == "40001":
+		return DBSerialization, true
+	case state == "55P03":
+		return DBLockTimeout, true
+	case strings.HasPrefix(state, "08"):
+		return DBConnection, true
+	}
+	return DBOther, false
+}
@@ -0,0 +1,87 @@
+package sunerror
+
+import (
+	"context"
+	"sync"
+)
+
+// Decorator 构造错误时在打印日志之前对错误做补充, 如从ctx中读取租户ID、机房等组织级信息, 不需要修改调用点
+// 通常返回e.WithOptions(...)的结果, 返回nil或e本身表示不修改; 在调用点选项与ContextWithOptions之后执行,
+// 此时调用点与堆栈已经确定, 新增的执行器(WithAsyncExecutor等)仍会执行
+type Decorator func(ctx context.Context, e *SunError) *SunError
+
+type decorator struct {
+	fn    Decorator
+	codes map[string]struct{}
+}
+
+var (
+	decoratorMu sync.RWMutex
+	decorators  []*decorator
+)
+
+// RegisterDecorator 注册装饰器, 按注册顺序执行; 指定codes时只对这些错误码生效, 否则对所有错误生效
+// 返回的函数用于注销该装饰器
+func RegisterDecorator(fn Decorator, codes ...string) (unregister func()) {
+	d := &decorator{fn: fn}
+	if len(codes) > 0 {
+		d.codes = make(map[string]struct{}, len(codes))
+		for _, code := range codes {
+			d.codes[code] = struct{}{}
+		}
+	}
+	decoratorMu.Lock()
+	decorators = append(decorators[:len(decorators):len(decorators)], d)
+	decoratorMu.Unlock()
+
+	return func() {
+		decoratorMu.Lock()
+		defer decoratorMu.Unlock()
+		for i, registered := range decorators {
+			if registered == d {
+				ds := make([]*decorator, 0, len(decorators)-1)
+				decorators = append(append(ds, decorators[:i]...), decorators[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// WithOptions 返回应用了opts的副本, 原错误不变, 副本不会再次打印日志也不执行执行器
+// 调用点与堆栈在构造时已经确定, WithSkipDepthOption、WithStackOption等选项对副本没有效果
+func (e *SunError) WithOptions(opts ...SunErrOption) *SunError {
+	out := e.clone()
+	for _, opt := range opts {
+		opt(out)
+	}
+	return out
+}
+
+// Redecorate 返回按当前注册的装饰器重新装饰的副本, 原错误不变, 副本不会再次打印日志也不执行执行器
+// 用于从JSON/protobuf还原的错误(还原时不执行装饰器), 如更新了映射表后重新分类历史错误
+func (e *SunError) Redecorate(ctx context.Context) *SunError {
+	out := e.clone()
+	out.decorate(ctx)
+	return out
+}
+
+// decorate 依次执行匹配的装饰器, 装饰器返回的副本整体替换构造中的错误, 保留原错误的Error()缓存与对象池归属
+func (e *SunError) decorate(ctx context.Context) {
+	decoratorMu.RLock()
+	ds := decorators
+	decoratorMu.RUnlock()
+	for _, d := range ds {
+		if d.codes != nil {
+			if _, ok := d.codes[e.code]; !ok {
+				continue
+			}
+		}
+		out := d.fn(ctx, e)
+		if out == nil || out == e {
+			continue
+		}
+		cache, pooled := e.errCache, e.pooled
+		*e = *out
+		e.errCache, e.pooled = cache, pooled
+	}
+}
@@ -0,0 +1,212 @@
+package sunerror
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// Definition 错误定义, 保存同一类错误不变的元数据(code/status/msg/level/kind等), 通常定义为包级变量
+//
+//	var ErrOrderNotFound = sunerror.Define("ORDER_1001", "FAILED", "order not found",
+//		sunerror.WithKindOption(sunerror.NotFoundKind), sunerror.WithLogEngine(logger))
+type Definition struct {
+	opts []SunErrOption
+	tmpl SunError // 应用opts后的模板, 只读
+}
+
+var (
+	defMu       sync.RWMutex
+	definitions = make(map[Code]*Definition)
+)
+
+// Define 创建错误定义, opts会应用到由该定义产生的每个错误上; 定义按code登记, 同一code重复定义时以最后一次为准
+func Define(code Code, status, msg string, opts ...SunErrOption) *Definition {
+	d := &Definition{opts: opts}
+	d.tmpl = SunError{
+		code:   string(code),
+		msg:    msg,
+		status: status,
+	}
+	d.tmpl.applyDefaults(loadConfig())
+	for _, opt := range opts {
+		opt(&d.tmpl)
+	}
+	defMu.Lock()
+	definitions[code] = d
+	defMu.Unlock()
+	return d
+}
+
+// LookupDefinition 按code查找由Define登记的错误定义
+func LookupDefinition(code Code) (*Definition, bool) {
+	defMu.RLock()
+	defer defMu.RUnlock()
+	d, ok := definitions[code]
+	return d, ok
+}
+
+func (d *Definition) GetCode() Code {
+	return Code(d.tmpl.code)
+}
+
+func (d *Definition) GetStatus() string {
+	return d.tmpl.status
+}
+
+func (d *Definition) GetMsg() string {
+	return d.tmpl.msg
+}
+
+func (d *Definition) GetLevel() SunErrLevel {
+	return d.tmpl.level
+}
+
+func (d *Definition) GetKind() SunErrKind {
+	return d.tmpl.kind
+}
+
+// New 按定义构造完整的SunError, 与NewSunError行为一致, opts在定义的选项之后应用
+func (d *Definition) New(ctx context.Context, opts ...SunErrOption) *SunError {
+	all := make([]SunErrOption, 0, len(d.opts)+len(opts))
+	all = append(append(all, d.opts...), opts...)
+	e := newSunError()
+	e.def = d
+	e.init(ctx, d.tmpl.code, d.tmpl.status, d.tmpl.msg, nil, all)
+	return e
+}
+
+// Error 使*Definition可以作为errors.Is的目标与errors.As的目标类型, 返回"code: msg"
+func (d *Definition) Error() string {
+	return d.tmpl.code + ": " + d.tmpl.msg
+}
+
+// GetDefinition 返回产生该错误的定义, 不是由Definition产生时返回nil
+func (e *SunError) GetDefinition() *Definition {
+	return e.def
+}
+
+// definition 产生该错误的定义, 不是由Definition产生(如从JSON/gRPC还原)时按code查找已登记的定义
+func (e *SunError) definition() *Definition {
+	if e.def != nil {
+		return e.def
+	}
+	d, _ := LookupDefinition(Code(e.code))
+	return d
+}
+
+// Is 支持errors.Is(err, ErrOrderNotFound), err链中的错误由该定义产生时为true
+func (e *SunError) Is(target error) bool {
+	d, ok := target.(*Definition)
+	return ok && d != nil && e.definition() == d
+}
+
+// As 支持errors.As(err, &def)取出产生错误的定义, 可以按定义分支处理
+//
+//	var def *sunerror.Definition
+//	if errors.As(err, &def) {
+//		switch def {
+//		case ErrOrderNotFound:
+//			...
+//		}
+//	}
+func (e *SunError) As(target interface{}) bool {
+	p, ok := target.(**Definition)
+	if !ok {
+		return false
+	}
+	d := e.definition()
+	if d == nil {
+		return false
+	}
+	*p = d
+	return true
+}
+
+// LightError 轻量级错误, 元数据共享自Definition, 只保存本次发生的detail与调用栈PC
+// 1. 构造时只分配自身与PC切片, 调用栈与detail在需要时才格式化
+// 2. 与SunError一样在构造时打印日志, 但不执行同步/异步执行器与全局钩子
+// 3. errors.As(err, &sunErr)时按需转换为*SunError, 依赖*SunError的工具函数可以直接使用
+type LightError struct {
+	def    *Definition
+	format string
+	args   []interface{}
+	pc     uintptr
+	stack  []uintptr
+	rawID  [8]byte
+}
+
+// Light 按定义构造轻量级错误, format与v为本次的detail
+func (d *Definition) Light(ctx context.Context, format string, v ...interface{}) *LightError {
+	e := &LightError{def: d, format: format, args: v}
+	newErrorID(&e.rawID)
+	if d.tmpl.storeStack {
+		e.stack = make([]uintptr, d.tmpl.stackRows)
+		e.stack = e.stack[:runtime.Callers(d.tmpl.depth, e.stack)]
+		if len(e.stack) > 0 {
+			e.pc = e.stack[0]
+		}
+	} else {
+		e.pc = callerPC(d.tmpl.depth)
+	}
+	if d.tmpl.shouldLog(loadConfig()) {
+		if log := d.tmpl.getLogFunc(); log != nil {
+			log(ctx, "%s", e)
+		}
+	}
+	return e
+}
+
+// GetDefinition 返回产生该错误的定义
+func (e *LightError) GetDefinition() *Definition {
+	return e.def
+}
+
+func (e *LightError) Error() string {
+	s := e.value()
+	return s.formatError()
+}
+
+// Is 支持errors.Is(err, ErrOrderNotFound), 与SunError相同
+func (e *LightError) Is(target error) bool {
+	d, ok := target.(*Definition)
+	return ok && d != nil && e.def == d
+}
+
+// As 支持errors.As(err, &sunErr), 转换出的*SunError不会再次打印日志; 也支持errors.As(err, &def)取出定义
+func (e *LightError) As(target interface{}) bool {
+	switch p := target.(type) {
+	case **SunError:
+		out := newSunError()
+		cache := out.errCache
+		*out = e.value()
+		out.errCache = cache
+		*p = out
+		return true
+	case **Definition:
+		*p = e.def
+		return true
+	}
+	return false
+}
+
+// value 展开为SunError值, 调用栈在这里才格式化
+func (e *LightError) value() SunError {
+	s := e.def.tmpl
+	s.def = e.def
+	s.pc = e.pc
+	s.rawID = e.rawID
+	s.hasRawID = true
+	s.asyncFns, s.syncFns = nil, nil
+	if len(e.format) > 0 {
+		s.detail = fmt.Sprintf(e.format, e.args...)
+		s.lazyDetail = nil
+	}
+	if s.storeStack {
+		s.stack = writeFrames(new(bytes.Buffer), e.stack, len(e.stack))
+		s.stackPCs = e.stack
+	}
+	return s
+}
@@ -0,0 +1,38 @@
+package sunerror
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestLightWithoutLogEngine(t *testing.T) {
+	old := DefaultConfig()
+	t.Cleanup(func() { Configure(old) })
+	cfg := old
+	cfg.LogEngine = nil
+	Configure(cfg)
+
+	d := Define("LIGHT_1", "404", "not found")
+	e := d.Light(context.Background(), "order=%d", 1)
+	if !strings.Contains(e.Error(), "LIGHT_1") {
+		t.Fatalf("Error() = %q", e.Error())
+	}
+}
+
+func TestLightDebugLevelGating(t *testing.T) {
+	logs := captureConfigLog(t)
+	d := Define("LIGHT_DEBUG", "400", "debug", WithLogLevelOption(DebugLevel))
+	d.Light(context.Background(), "")
+	if got := logs(); len(got) != 0 {
+		t.Fatalf("DebugLevel definition logged without EnableDebug: %q", got)
+	}
+
+	cfg := DefaultConfig()
+	cfg.EnableDebug = true
+	Configure(cfg)
+	d.Light(context.Background(), "")
+	if got := logs(); len(got) != 1 {
+		t.Fatalf("logs = %q, want one line with EnableDebug", got)
+	}
+}
@@ -0,0 +1,63 @@
+package sunerror
+
+// Normalize 返回只保留数据字段的副本, 用于比较与序列化前后的校验
+// 延迟格式化的detail、调用点与自动生成的errorID展开为字符串; 堆栈、执行器、日志引擎等进程内的状态被清空
+func Normalize(e *SunError) *SunError {
+	if e == nil {
+		return nil
+	}
+	out := newSunError()
+	out.code = e.code
+	out.status = e.status
+	out.msg = e.msg
+	out.level = e.level
+	out.detail = e.GetDetail()
+	out.fnName = e.GetFnName()
+	out.channelCode = e.channelCode
+	out.channelMsg = e.GetChannelMsg()
+	out.channelCall = e.channelCall
+	out.remote = e.remote
+	out.errorID = e.GetErrorID()
+	out.traceID = e.traceID
+	out.userMsg = e.userMsg
+	out.docsURL = e.docsURL
+	out.kind = e.kind
+	out.retryable = e.retryable
+	out.sideEffect = e.sideEffect
+	out.violations = e.violations
+	out.retryAfter = e.retryAfter
+	out.hasRetryAfter = e.hasRetryAfter
+	out.def = e.def
+	out.noLog = true
+	out.depth = 2
+	out.stackRows = 10
+	return out
+}
+
+// Equal 比较两个错误的数据字段(三元组、detail、fnName、下游信息与调用信息、远端的fnName、errorID、traceID、userMsg、docsURL、kind、retryable、sideEffect、retryAfter、violations、level)
+// 不比较堆栈与执行器等进程内的状态, 序列化再还原后的错误与原错误Equal即没有丢失字段
+func Equal(a, b *SunError) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.code == b.code &&
+		a.status == b.status &&
+		a.msg == b.msg &&
+		a.level == b.level &&
+		a.GetDetail() == b.GetDetail() &&
+		a.GetFnName() == b.GetFnName() &&
+		a.channelCode == b.channelCode &&
+		a.GetChannelMsg() == b.GetChannelMsg() &&
+		equalChannelCall(a.channelCall, b.channelCall) &&
+		equalRemote(a.remote, b.remote) &&
+		a.GetErrorID() == b.GetErrorID() &&
+		a.traceID == b.traceID &&
+		a.userMsg == b.userMsg &&
+		a.docsURL == b.docsURL &&
+		a.kind == b.kind &&
+		a.retryable == b.retryable &&
+		a.sideEffect == b.sideEffect &&
+		a.retryAfter == b.retryAfter &&
+		a.hasRetryAfter == b.hasRetryAfter &&
+		equalViolations(a.violations, b.violations)
+}
@@ -0,0 +1,157 @@
+package sunerror
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrGroup构造错误时使用的错误码
+const (
+	// GroupPanicCode 协程发生panic
+	GroupPanicCode Code = "GROUP_PANIC"
+	// GroupFailedCode 协程返回了非SunError的错误
+	GroupFailedCode Code = "GROUP_FAILED"
+	// GroupFailedStatus 协程失败的status
+	GroupFailedStatus = "FAILED"
+)
+
+// SunErrors 多个SunError组成的错误, errors.Is/As可以匹配到其中任意一个
+type SunErrors []*SunError
+
+// Error 错误数与各错误的Error(), 以"; "分隔
+func (es SunErrors) Error() string {
+	var b strings.Builder
+	b.WriteString(strconv.Itoa(len(es)))
+	b.WriteString(" errors: ")
+	for i, e := range es {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(e.Error())
+	}
+	return b.String()
+}
+
+// Unwrap 返回其中的全部错误, 供errors.Is/As遍历
+func (es SunErrors) Unwrap() []error {
+	errs := make([]error, len(es))
+	for i, e := range es {
+		errs[i] = e
+	}
+	return errs
+}
+
+// Codes 按顺序返回各错误的错误码
+func (es SunErrors) Codes() []string {
+	codes := make([]string, len(es))
+	for i, e := range es {
+		codes[i] = e.code
+	}
+	return codes
+}
+
+// MaxLevel 其中最高的错误等级, 为空时返回UnknownLevel
+func (es SunErrors) MaxLevel() SunErrLevel {
+	level := UnknownLevel
+	for _, e := range es {
+		if e.level > level {
+			level = e.level
+		}
+	}
+	return level
+}
+
+// ErrGroup 与golang.org/x/sync/errgroup用法相同的协程组, 区别在于:
+// 1. 协程中的panic被recover并转换为GroupPanicCode的SunError, 不会导致进程退出
+// 2. Wait返回全部协程的错误(SunErrors), 而不只是第一个; SunError原样保留错误码与等级, 其他错误转换为GroupFailedCode
+// 与errgroup.WithContext相同, 第一个错误出现时取消Group返回的ctx
+type ErrGroup struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	opts   []SunErrOption
+	wg     sync.WaitGroup
+	sem    chan struct{}
+
+	mu   sync.Mutex
+	errs SunErrors
+}
+
+// Group 创建协程组, 返回的ctx在第一个错误出现或Wait返回时被取消
+// opts用于panic与非SunError错误转换得到的SunError
+func Group(ctx context.Context, opts ...SunErrOption) (*ErrGroup, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &ErrGroup{ctx: ctx, cancel: cancel, opts: opts}, ctx
+}
+
+// SetLimit 限制同时执行的协程数, n为负数时不限制; 必须在Go之前调用
+func (g *ErrGroup) SetLimit(n int) {
+	if n < 0 {
+		g.sem = nil
+		return
+	}
+	g.sem = make(chan struct{}, n)
+}
+
+// Go 在新协程中执行fn, 设置了SetLimit且已达上限时阻塞到有协程结束
+func (g *ErrGroup) Go(fn func() error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	g.wg.Add(1)
+	go func() {
+		defer func() {
+			if g.sem != nil {
+				<-g.sem
+			}
+			g.wg.Done()
+		}()
+		g.add(g.call(fn))
+	}()
+}
+
+// Wait 等待全部协程结束, 按出现顺序返回全部错误(SunErrors), 没有错误时返回nil
+func (g *ErrGroup) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.errs) == 0 {
+		return nil
+	}
+	return append(SunErrors(nil), g.errs...)
+}
+
+// call 执行fn, 将panic与非SunError的错误转换为SunError
+func (g *ErrGroup) call(fn func() error) (sunErr *SunError) {
+	defer func() {
+		if r := recover(); r != nil {
+			fields := []SunErrOption{WithDetailOption("panic: %v", r), WithStackRows(32)}
+			sunErr = NewSunErrorAt(g.ctx, panicPC(), GroupPanicCode, GroupFailedStatus, "goroutine panic", append(fields, g.opts...)...)
+		}
+	}()
+	err := fn()
+	if err == nil {
+		return nil
+	}
+	if errors.As(err, &sunErr) {
+		return sunErr
+	}
+	fields := []SunErrOption{WithCauseOption(err), WithStackOption(false)}
+	return NewSunError(g.ctx, GroupFailedCode, GroupFailedStatus, err.Error(), append(fields, g.opts...)...)
+}
+
+func (g *ErrGroup) add(e *SunError) {
+	if e == nil {
+		return
+	}
+	g.mu.Lock()
+	first := len(g.errs) == 0
+	g.errs = append(g.errs, e)
+	g.mu.Unlock()
+	if first {
+		g.cancel()
+	}
+}
@@ -0,0 +1,68 @@
+package sunerror
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// 命令行工具的默认退出码
+const (
+	// ExitFailure 一般错误
+	ExitFailure = 1
+	// ExitUsage 参数校验失败
+	ExitUsage = 2
+)
+
+var (
+	exitCodeMu sync.RWMutex
+	exitCodes  = map[Code]int{}
+
+	exitOutput io.Writer = os.Stderr
+	osExit               = os.Exit
+)
+
+// RegisterExitCode 为指定错误码注册命令行退出码
+func RegisterExitCode(code Code, exitCode int) {
+	exitCodeMu.Lock()
+	defer exitCodeMu.Unlock()
+	exitCodes[code] = exitCode
+}
+
+// ExitCode 返回err对应的退出码: nil为0, 已注册的错误码使用注册值, 参数校验失败为2, 其他为1
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var sunErr *SunError
+	if !errors.As(err, &sunErr) {
+		return ExitFailure
+	}
+	exitCodeMu.RLock()
+	exitCode, ok := exitCodes[Code(sunErr.code)]
+	exitCodeMu.RUnlock()
+	if ok {
+		return exitCode
+	}
+	if sunErr.kind == ValidationKind {
+		return ExitUsage
+	}
+	return ExitFailure
+}
+
+// HandleMain 在main函数末尾调用, err不为nil时输出到stderr并以ExitCode(err)退出
+// SunError只输出code与msg, 完整信息已在构造时打印到日志
+func HandleMain(err error) {
+	if err == nil {
+		return
+	}
+	var sunErr *SunError
+	if errors.As(err, &sunErr) {
+		fmt.Fprintf(exitOutput, "error: %s (code=%s, errorID=%s)\n", sunErr.msg, sunErr.code, sunErr.GetErrorID())
+	} else {
+		fmt.Fprintf(exitOutput, "error: %v\n", err)
+	}
+	osExit(ExitCode(err))
+}
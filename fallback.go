@@ -0,0 +1,63 @@
+package sunerror
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// FallbackFunc 降级处理, 返回降级结果(如缓存数据/默认值); 返回error表示无法降级
+type FallbackFunc func(ctx context.Context, e *SunError) (interface{}, error)
+
+type fallback struct {
+	fn FallbackFunc
+}
+
+var (
+	fallbackMu sync.RWMutex
+	fallbacks  = map[SunErrKind]*fallback{}
+)
+
+// OnKind 注册某一分类错误的降级处理, 由HandleWithFallback调用; 同一分类重复注册时以最后一次为准
+// 返回的函数用于注销, 已被之后的注册覆盖时不做任何事
+//
+//	sunerror.OnKind(sunerror.DownstreamKind, func(ctx context.Context, e *sunerror.SunError) (interface{}, error) {
+//		return cache.Get(ctx, key)
+//	})
+func OnKind(kind SunErrKind, fn FallbackFunc) (unregister func()) {
+	f := &fallback{fn: fn}
+	fallbackMu.Lock()
+	fallbacks[kind] = f
+	fallbackMu.Unlock()
+	return func() {
+		fallbackMu.Lock()
+		defer fallbackMu.Unlock()
+		if fallbacks[kind] == f {
+			delete(fallbacks, kind)
+		}
+	}
+}
+
+// HandleWithFallback 执行fn, fn返回的错误是SunError且其分类注册了降级处理时, 以降级结果代替错误返回
+// 降级处理也失败时返回原错误, 并在detail中追加降级失败的原因; 非SunError与未注册分类的错误原样返回
+func HandleWithFallback(ctx context.Context, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	result, err := fn(ctx)
+	if err == nil {
+		return result, nil
+	}
+	var sunErr *SunError
+	if !errors.As(err, &sunErr) {
+		return result, err
+	}
+	fallbackMu.RLock()
+	f := fallbacks[sunErr.kind]
+	fallbackMu.RUnlock()
+	if f == nil {
+		return result, err
+	}
+	fallbackResult, fallbackErr := f.fn(ctx, sunErr)
+	if fallbackErr != nil {
+		return result, sunErr.AppendDetail("fallback failed: %v", fallbackErr)
+	}
+	return fallbackResult, nil
+}
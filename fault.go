@@ -0,0 +1,85 @@
+package sunerror
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+)
+
+// FaultEnv 通过环境变量注入故障, 格式为 注入点=错误码, 多个之间以逗号分隔, 如
+// SUNERROR_FAULTS=payment.charge=PAY_TIMEOUT,stock.lock=STOCK_CONFLICT
+// 错误码需要已通过Define登记, 未登记的错误码会以该code构造一个不打印日志的通用SunError
+const FaultEnv = "SUNERROR_FAULTS"
+
+// FaultStatus与FaultMsg 环境变量中的错误码未通过Define登记时, 通用SunError使用的status与msg
+const (
+	FaultStatus = "FAULT_INJECTED"
+	FaultMsg    = "injected fault"
+)
+
+type faultKey struct{}
+
+// InjectFault 返回在注入点point强制返回def错误的ctx, 供混沌/集成测试使用, 不影响其他ctx
+//
+//	ctx = sunerror.InjectFault(ctx, "payment.charge", ErrPayTimeout)
+//	// payment代码中
+//	if err := sunerror.MaybeFail(ctx, "payment.charge"); err != nil {
+//		return err
+//	}
+func InjectFault(ctx context.Context, point string, def *Definition) context.Context {
+	parent, _ := ctx.Value(faultKey{}).(map[string]*Definition)
+	faults := make(map[string]*Definition, len(parent)+1)
+	for p, d := range parent {
+		faults[p] = d
+	}
+	faults[point] = def
+	return context.WithValue(ctx, faultKey{}, faults)
+}
+
+// MaybeFail 注入点point注入了故障时返回对应的SunError, 否则返回nil
+// ctx中注入的故障优先于环境变量; 返回的错误与正常构造的一样会打印日志、执行执行器, detail中记录注入点
+func MaybeFail(ctx context.Context, point string) error {
+	if faults, ok := ctx.Value(faultKey{}).(map[string]*Definition); ok {
+		if def, ok := faults[point]; ok && def != nil {
+			return def.New(ctx, WithSkipDepthOption(1), WithDetailOption("fault injected at %s", point))
+		}
+	}
+	raw := os.Getenv(FaultEnv)
+	if len(raw) == 0 {
+		return nil
+	}
+	code, ok := envFaults(raw)[point]
+	if !ok {
+		return nil
+	}
+	if def, ok := LookupDefinition(Code(code)); ok {
+		return def.New(ctx, WithSkipDepthOption(1), WithDetailOption("fault injected at %s", point))
+	}
+	return NewSunError(ctx, Code(code), FaultStatus, FaultMsg, WithNoLogOption(), WithSkipDepthOption(1), WithDetailOption("fault injected at %s", point))
+}
+
+var (
+	faultEnvMu     sync.Mutex
+	faultEnvRaw    string
+	faultEnvParsed map[string]string
+)
+
+// envFaults 解析环境变量, 结果按原始字符串缓存, 环境变量变化时重新解析
+func envFaults(raw string) map[string]string {
+	faultEnvMu.Lock()
+	defer faultEnvMu.Unlock()
+	if raw == faultEnvRaw && faultEnvParsed != nil {
+		return faultEnvParsed
+	}
+	faults := make(map[string]string)
+	for _, item := range strings.Split(raw, ",") {
+		point, code, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok || len(point) == 0 || len(code) == 0 {
+			continue
+		}
+		faults[point] = code
+	}
+	faultEnvRaw, faultEnvParsed = raw, faults
+	return faults
+}
@@ -0,0 +1,36 @@
+package sunerror
+
+import "runtime"
+
+// FuncFormat 调用点格式化为fnName的格式, 可以按位组合
+// 默认为 文件名:行号:函数名(), 不同包中的同名文件与同名函数无法区分时可以加上包路径或完整的文件路径
+type FuncFormat uint8
+
+const (
+	// FuncPackage 函数名包含完整的包路径, 如github.com/x/order.(*Service).Create()
+	FuncPackage FuncFormat = 1 << iota
+	// FuncFullPath 使用完整的文件路径而不是文件名
+	FuncFullPath
+)
+
+// WithCallerFullPathOption fnName使用完整的文件路径与包含包路径的函数名
+func WithCallerFullPathOption() SunErrOption {
+	return func(e *SunError) {
+		e.fnFormat = FuncPackage | FuncFullPath
+	}
+}
+
+// WithFuncFormatOption 设置fnName的格式, 不设置时使用Config.FuncFormat
+func WithFuncFormatOption(format FuncFormat) SunErrOption {
+	return func(e *SunError) {
+		e.fnFormat = format
+	}
+}
+
+// WithFuncFormatterOption 自定义fnName的格式化函数, 参数为产生错误的栈帧; 结果不缓存, 每次GetFnName都会调用
+// StableStack/StripStack模式与WithFuncNameOption优先
+func WithFuncFormatterOption(formatter func(frame runtime.Frame) string) SunErrOption {
+	return func(e *SunError) {
+		e.fnFormatter = formatter
+	}
+}
@@ -0,0 +1,13 @@
+module github.com/sjmshsh/sunerror
+
+go 1.22.0
+
+require (
+	golang.org/x/tools v0.30.0
+	google.golang.org/protobuf v1.34.2
+)
+
+require (
+	golang.org/x/mod v0.23.0 // indirect
+	golang.org/x/sync v0.11.0 // indirect
+)
@@ -0,0 +1,168 @@
+package sunerror
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxGroupErrors 每个分组最多保存的错误数, 超出的只计数
+	maxGroupErrors = 256
+	// maxGroups 同时保存的分组数上限, 超出时淘汰最早创建的分组
+	maxGroups = 4096
+	// groupTTL 分组创建后的保存时间, 超时未被GroupErrors取出的分组会被清理
+	groupTTL = 10 * time.Minute
+)
+
+type groupEntry struct {
+	errs    []*SunError
+	dropped int
+	created time.Time
+}
+
+var (
+	groupMu    sync.Mutex
+	groups     = make(map[string]*groupEntry)
+	groupSwept time.Time
+)
+
+// NewGroupID 生成扇出调用的分组ID
+func NewGroupID() string {
+	var id [8]byte
+	newErrorID(&id)
+	return hex.EncodeToString(id[:])
+}
+
+// WithGroupOption 将错误关联到扇出调用的分组, 父协程通过GroupErrors取出同一分组中各子协程产生的错误
+// 分组中的错误在GroupErrors之前一直被持有, 每个分组最多保存256个, 父协程应确保调用GroupErrors(如defer)
+// 忘记取出的分组在10分钟后清理, 同时存在的分组超过4096个时淘汰最早创建的分组
+//
+//	groupID := sunerror.NewGroupID()
+//	for _, item := range items {
+//		go func() { ... sunerror.NewSunError(ctx, code, status, msg, sunerror.WithGroupOption(groupID)) ... }()
+//	}
+//	wg.Wait()
+//	err := sunerror.AggregateErrors(ctx, sunerror.GroupErrors(groupID), "BATCH_1001", "FAILED", "batch partially failed")
+func WithGroupOption(groupID string) SunErrOption {
+	return func(e *SunError) {
+		e.groupID = groupID
+	}
+}
+
+// GetGroupID 错误所属的分组, 未设置WithGroupOption时为空
+func (e *SunError) GetGroupID() string {
+	return e.groupID
+}
+
+// recordGroup 构造完成后登记到所属分组, 对象池中的错误归还后会被复用, 不参与分组
+func recordGroup(e *SunError) {
+	if len(e.groupID) == 0 || e.pooled != nil {
+		return
+	}
+	groupMu.Lock()
+	defer groupMu.Unlock()
+	g := groups[e.groupID]
+	if g == nil {
+		now := time.Now()
+		evictGroups(now)
+		g = &groupEntry{created: now}
+		groups[e.groupID] = g
+	}
+	if len(g.errs) >= maxGroupErrors {
+		g.dropped++
+		return
+	}
+	g.errs = append(g.errs, e)
+}
+
+// evictGroups 创建新分组前调用, 每个groupTTL清理一次超时的分组, 分组数达到上限时再淘汰最早创建的分组, 调用方持有groupMu
+func evictGroups(now time.Time) {
+	if now.Sub(groupSwept) >= groupTTL || len(groups) >= maxGroups {
+		groupSwept = now
+		for id, g := range groups {
+			if now.Sub(g.created) >= groupTTL {
+				delete(groups, id)
+			}
+		}
+	}
+	if len(groups) < maxGroups {
+		return
+	}
+	var oldestID string
+	var oldest time.Time
+	for id, g := range groups {
+		if len(oldestID) == 0 || g.created.Before(oldest) {
+			oldestID, oldest = id, g.created
+		}
+	}
+	delete(groups, oldestID)
+}
+
+// GroupErrors 按产生顺序取出分组中的全部错误并删除该分组, 没有错误时返回nil
+func GroupErrors(groupID string) []*SunError {
+	groupMu.Lock()
+	g := groups[groupID]
+	delete(groups, groupID)
+	groupMu.Unlock()
+	if g == nil {
+		return nil
+	}
+	return g.errs
+}
+
+// AggregateErrors 将多个错误汇总为一个SunError, errs为空时返回nil
+// detail为各错误码的数量统计, 等级取最高的等级, 所有错误分类相同时沿用该分类, 全部可重试且没有副作用时可重试
+// 原始错误以errors.Join保存, errors.Is/As可以匹配到其中任意一个
+func AggregateErrors(ctx context.Context, errs []*SunError, code Code, status, msg string, opts ...SunErrOption) *SunError {
+	if len(errs) == 0 {
+		return nil
+	}
+	level := errs[0].level
+	kind := errs[0].kind
+	retryable := true
+	counts := make(map[string]int)
+	joined := make([]error, len(errs))
+	for i, e := range errs {
+		joined[i] = e
+		counts[e.code]++
+		if e.level > level {
+			level = e.level
+		}
+		if e.kind != kind {
+			kind = UnknownKind
+		}
+		retryable = retryable && e.retryable && !e.sideEffect
+	}
+	codes := make([]string, 0, len(counts))
+	for c := range counts {
+		codes = append(codes, c)
+	}
+	sort.Strings(codes)
+	var b strings.Builder
+	b.WriteString(strconv.Itoa(len(errs)))
+	b.WriteString(" errors:")
+	for i, c := range codes {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteByte(' ')
+		b.WriteString(c)
+		b.WriteString(" x")
+		b.WriteString(strconv.Itoa(counts[c]))
+	}
+	fields := []SunErrOption{
+		WithDetailOption("%s", b.String()),
+		WithLogLevelOption(level),
+		WithKindOption(kind),
+		WithRetryableOption(retryable),
+		WithCauseOption(errors.Join(joined...)),
+		WithSkipDepthOption(1),
+	}
+	return NewSunError(ctx, code, status, msg, append(fields, opts...)...)
+}
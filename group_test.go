@@ -0,0 +1,73 @@
+package sunerror
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func resetGroups(t *testing.T) {
+	groupMu.Lock()
+	groups = make(map[string]*groupEntry)
+	groupSwept = time.Time{}
+	groupMu.Unlock()
+	t.Cleanup(func() {
+		groupMu.Lock()
+		groups = make(map[string]*groupEntry)
+		groupMu.Unlock()
+	})
+}
+
+func newGroupError(groupID string) *SunError {
+	return NewSunError(context.Background(), "GROUP_1", "500", "child failed", WithNoLogOption(), WithStackOption(false), WithGroupOption(groupID))
+}
+
+func TestGroupErrorsCollects(t *testing.T) {
+	resetGroups(t)
+	groupID := NewGroupID()
+	newGroupError(groupID)
+	newGroupError(groupID)
+	if errs := GroupErrors(groupID); len(errs) != 2 {
+		t.Fatalf("GroupErrors returned %d errors, want 2", len(errs))
+	}
+	if errs := GroupErrors(groupID); errs != nil {
+		t.Fatalf("group not deleted after GroupErrors")
+	}
+}
+
+func TestGroupsEvictExpired(t *testing.T) {
+	resetGroups(t)
+	newGroupError("stale")
+	groupMu.Lock()
+	groups["stale"].created = time.Now().Add(-2 * groupTTL)
+	groupSwept = time.Time{}
+	groupMu.Unlock()
+
+	newGroupError("fresh")
+	if errs := GroupErrors("stale"); errs != nil {
+		t.Fatalf("expired group was not evicted")
+	}
+	if errs := GroupErrors("fresh"); len(errs) != 1 {
+		t.Fatalf("fresh group has %d errors, want 1", len(errs))
+	}
+}
+
+func TestGroupsBounded(t *testing.T) {
+	resetGroups(t)
+	for i := 0; i < maxGroups+10; i++ {
+		newGroupError(strconv.Itoa(i))
+	}
+	groupMu.Lock()
+	n := len(groups)
+	groupMu.Unlock()
+	if n > maxGroups {
+		t.Fatalf("len(groups) = %d, want at most %d", n, maxGroups)
+	}
+	if errs := GroupErrors("0"); errs != nil {
+		t.Fatalf("oldest group was not evicted")
+	}
+	if errs := GroupErrors(strconv.Itoa(maxGroups + 9)); len(errs) != 1 {
+		t.Fatalf("newest group missing")
+	}
+}
@@ -0,0 +1,39 @@
+package sunerror
+
+import (
+	"context"
+	"time"
+)
+
+// deadlineNear ctx的剩余时间是否已低于guard, 返回剩余时间; guard<=0或ctx没有截止时间时为false
+func deadlineNear(ctx context.Context, guard time.Duration) (time.Duration, bool) {
+	if guard <= 0 || ctx == nil {
+		return 0, false
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	remaining := time.Until(deadline)
+	return remaining, remaining < guard
+}
+
+// skippedMarker 因截止时间临近跳过抓取时, 在堆栈与负载的位置输出的标记
+func skippedMarker(what string, remaining time.Duration) string {
+	if remaining <= 0 {
+		return what + " skipped: ctx deadline exceeded"
+	}
+	return what + " skipped: ctx deadline in " + remaining.Round(time.Microsecond).String()
+}
+
+// skippedPayload 以标记代替尚未序列化的负载, 原来为nil的一侧仍为空
+func skippedPayload(p *payloadCapture, marker string) *payloadCapture {
+	var req, resp string
+	if p.req != nil {
+		req = marker
+	}
+	if p.resp != nil {
+		resp = marker
+	}
+	return restoredPayload(req, resp)
+}
@@ -0,0 +1,82 @@
+package sunerror
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"sync/atomic"
+)
+
+// HeaderKey 跨服务传递EncodeHeader结果时建议使用的HTTP header/gRPC metadata键
+const HeaderKey = "X-Sun-Error"
+
+// headerVersion EncodeHeader编码格式的版本号, 格式变化时递增
+const headerVersion = 1
+
+// ErrInvalidHeader DecodeHeader的输入不是EncodeHeader的结果
+var ErrInvalidHeader = errors.New("sunerror: invalid error header")
+
+// traceIDExtractor 从ctx中获取链路追踪ID的函数, 未设置时为nil
+var traceIDExtractor atomic.Value
+
+// SetTraceIDExtractor 设置从ctx中获取链路追踪ID的函数, 未通过WithTraceIDOption设置traceID时构造错误时调用
+// 例如接入OpenTelemetry时返回trace.SpanContextFromContext(ctx).TraceID().String(); 传nil时不再自动获取
+func SetTraceIDExtractor(fn func(ctx context.Context) string) {
+	traceIDExtractor.Store(fn)
+}
+
+func traceIDFromContext(ctx context.Context) string {
+	fn, _ := traceIDExtractor.Load().(func(ctx context.Context) string)
+	if fn == nil || ctx == nil {
+		return ""
+	}
+	return fn(ctx)
+}
+
+// EncodeHeader 将三元组、errorID与traceID编码为紧凑的URL安全字符串, 可以直接放入HTTP header或gRPC metadata
+// 只包含跨服务关联同一个错误所需的最少字段, 需要完整内容时使用MarshalText
+func (e *SunError) EncodeHeader() string {
+	fields := [...]string{e.code, e.status, e.msg, e.GetErrorID(), e.traceID}
+	n := 1
+	for _, f := range fields {
+		n += binary.MaxVarintLen64 + len(f)
+	}
+	buf := make([]byte, 1, n)
+	buf[0] = headerVersion
+	for _, f := range fields {
+		buf = binary.AppendUvarint(buf, uint64(len(f)))
+		buf = append(buf, f...)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// DecodeHeader 从EncodeHeader的结果还原错误, 沿用原errorID与traceID, 不打印日志也不执行执行器
+// 输入不合法时返回ErrInvalidHeader
+func DecodeHeader(header string) (*SunError, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(header)
+	if err != nil || len(buf) == 0 || buf[0] != headerVersion {
+		return nil, ErrInvalidHeader
+	}
+	buf = buf[1:]
+	var fields [5]string
+	for i := range fields {
+		l, n := binary.Uvarint(buf)
+		if n <= 0 || l > uint64(len(buf)-n) {
+			return nil, ErrInvalidHeader
+		}
+		fields[i] = string(buf[n : n+int(l)])
+		buf = buf[n+int(l):]
+	}
+	if len(buf) != 0 {
+		return nil, ErrInvalidHeader
+	}
+	return FromRecord(Record{
+		Code:    fields[0],
+		Status:  fields[1],
+		Msg:     fields[2],
+		ErrorID: fields[3],
+		TraceID: fields[4],
+		Level:   ErrorLevel,
+	})
+}
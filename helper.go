@@ -0,0 +1,92 @@
+package sunerror
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	hasHelpers  atomic.Bool
+	helperPCs   sync.Map // MarkHelper的调用点, 避免每次调用都展开栈帧
+	helperFuncs sync.Map // 标记为辅助函数的函数全名
+
+	helperPkgMu sync.RWMutex
+	helperPkgs  []string
+)
+
+// MarkHelper 将调用它的函数标记为构造错误的辅助函数, 类似testing.T.Helper
+// 确定fnName与堆栈时跳过辅助函数的栈帧, 封装了NewSunError的函数不需要再计算WithSkipDepthOption
+//
+//	func BizError(ctx context.Context, code string) *sunerror.SunError {
+//		sunerror.MarkHelper()
+//		return sunerror.NewSunError(ctx, code, "FAILED", "biz error")
+//	}
+func MarkHelper() {
+	pc := callerPC(2)
+	if _, ok := helperPCs.Load(pc); ok {
+		return
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	helperFuncs.Store(frame.Function, struct{}{})
+	helperPCs.Store(pc, struct{}{})
+	hasHelpers.Store(true)
+}
+
+// RegisterHelperPackage 将整个包中的函数都作为辅助函数跳过, pkgPath为完整的包路径, 如github.com/x/errs
+// 适合统一封装了错误构造的公共库, 在初始化时调用
+func RegisterHelperPackage(pkgPath string) {
+	helperPkgMu.Lock()
+	defer helperPkgMu.Unlock()
+	helperPkgs = append(helperPkgs, pkgPath)
+	hasHelpers.Store(true)
+}
+
+// helperSkip 跳过skip层(含义与runtime.Caller一致)后, 连续属于辅助函数的栈帧数, 没有注册辅助函数时不展开调用栈
+func helperSkip(skip int) int {
+	if !hasHelpers.Load() {
+		return 0
+	}
+	var pcs [16]uintptr
+	n := runtime.Callers(skip+1, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	skipped := 0
+	for {
+		frame, more := frames.Next()
+		if !isHelper(frame.Function) {
+			return skipped
+		}
+		skipped++
+		if !more {
+			return skipped
+		}
+	}
+}
+
+func isHelper(function string) bool {
+	if len(function) == 0 {
+		return false
+	}
+	if _, ok := helperFuncs.Load(function); ok {
+		return true
+	}
+	pkg := funcPackage(function)
+	helperPkgMu.RLock()
+	defer helperPkgMu.RUnlock()
+	for _, p := range helperPkgs {
+		if p == pkg {
+			return true
+		}
+	}
+	return false
+}
+
+// funcPackage 函数全名中的包路径, 如github.com/x/errs.(*T).New的包路径为github.com/x/errs
+func funcPackage(function string) string {
+	slash := strings.LastIndexByte(function, '/') + 1
+	if dot := strings.IndexByte(function[slash:], '.'); dot >= 0 {
+		return function[:slash+dot]
+	}
+	return function
+}
@@ -0,0 +1,115 @@
+package sunerror
+
+import (
+	"context"
+	"sync"
+)
+
+type globalHook struct {
+	fn       func(ctx context.Context, sunError *SunError)
+	minLevel SunErrLevel
+	codes    map[string]struct{}
+}
+
+// HookOption 全局钩子的过滤条件
+type HookOption func(h *globalHook)
+
+// WithHookMinLevel 只对不低于level的错误触发
+func WithHookMinLevel(level SunErrLevel) HookOption {
+	return func(h *globalHook) {
+		h.minLevel = level
+	}
+}
+
+// WithHookCodes 只对指定错误码触发
+func WithHookCodes(codes ...string) HookOption {
+	return func(h *globalHook) {
+		if h.codes == nil {
+			h.codes = make(map[string]struct{}, len(codes))
+		}
+		for _, code := range codes {
+			h.codes[code] = struct{}{}
+		}
+	}
+}
+
+var (
+	hookMu      sync.RWMutex
+	globalHooks []*globalHook
+)
+
+// RegisterGlobalHook 注册对所有SunError生效的异步钩子(如metrics/Sentry上报), 在WithAsyncExecutor设置的执行器之后执行
+// 返回的函数用于注销该钩子
+func RegisterGlobalHook(fn func(ctx context.Context, sunError *SunError), opts ...HookOption) (unregister func()) {
+	h := &globalHook{fn: fn}
+	for _, opt := range opts {
+		opt(h)
+	}
+	hookMu.Lock()
+	globalHooks = append(globalHooks[:len(globalHooks):len(globalHooks)], h)
+	hookMu.Unlock()
+
+	return func() {
+		hookMu.Lock()
+		defer hookMu.Unlock()
+		for i, registered := range globalHooks {
+			if registered == h {
+				hooks := make([]*globalHook, 0, len(globalHooks)-1)
+				globalHooks = append(append(hooks, globalHooks[:i]...), globalHooks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func (h *globalHook) match(e *SunError) bool {
+	if e.level < h.minLevel {
+		return false
+	}
+	if p := e.tenantPolicy; p != nil && e.level < p.AlertMinLevel {
+		return false
+	}
+	if h.codes == nil {
+		return true
+	}
+	_, ok := h.codes[e.code]
+	return ok
+}
+
+// RunGlobalHooks 在当前协程中依次执行与e匹配的全局钩子, 返回执行的钩子数; 不经过采样, 钩子的panic与异步执行时一样交给panic处理函数
+// 构造错误时全局钩子已经自动执行, 只用于重放从JSON/protobuf还原的错误, 如上报管道故障恢复后重新上报
+func RunGlobalHooks(ctx context.Context, e *SunError) int {
+	hookMu.RLock()
+	hooks := globalHooks
+	hookMu.RUnlock()
+	n := 0
+	for _, h := range hooks {
+		if h.match(e) {
+			fn := h.fn
+			e.safeCall(ctx, func() { fn(ctx, e) })
+			n++
+		}
+	}
+	return n
+}
+
+// executors 返回本次需要执行的异步执行器: WithAsyncExecutor设置的执行器及匹配的全局钩子
+func (e *SunError) executors() []asyncExecutor {
+	hookMu.RLock()
+	hooks := globalHooks
+	hookMu.RUnlock()
+	if len(hooks) == 0 {
+		return e.asyncFns
+	}
+	fns := e.asyncFns[:len(e.asyncFns):len(e.asyncFns)]
+	for _, h := range hooks {
+		if h.match(e) {
+			fn := h.fn
+			fns = append(fns, func(ctx context.Context, sunError *SunError) error {
+				fn(ctx, sunError)
+				return nil
+			})
+		}
+	}
+	return fns
+}
@@ -0,0 +1,57 @@
+package sunerror
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ResponseBody 对外返回的JSON错误响应体, HTTP中间件与grpc-gateway共用同一结构
+type ResponseBody struct {
+	Code        string           `json:"code"`
+	Status      string           `json:"status"`
+	Msg         string           `json:"msg"`
+	Detail      string           `json:"detail,omitempty"`
+	ChannelCode string           `json:"channelCode,omitempty"`
+	ChannelMsg  string           `json:"channelMsg,omitempty"`
+	ErrorID     string           `json:"errorID,omitempty"`
+	UserMsg     string           `json:"userMsg,omitempty"`
+	DocsURL     string           `json:"docsURL,omitempty"`
+	Violations  []FieldViolation `json:"violations,omitempty"`
+}
+
+// ToResponseBody 转换为对外返回的响应体, 不包含函数名与堆栈; 合规模式下也不包含detail与下游信息, 见External
+func (e *SunError) ToResponseBody() ResponseBody {
+	e = e.External()
+	return ResponseBody{
+		Code:        e.code,
+		Status:      e.status,
+		Msg:         e.msg,
+		Detail:      e.GetDetail(),
+		ChannelCode: e.channelCode,
+		ChannelMsg:  e.GetChannelMsg(),
+		ErrorID:     e.GetErrorID(),
+		UserMsg:     e.userMsg,
+		DocsURL:     e.docsURL,
+		Violations:  e.violations,
+	}
+}
+
+// WriteJSON 以JSON格式将错误写入http.ResponseWriter, 设置了建议重试间隔时同时写入Retry-After
+func WriteJSON(w http.ResponseWriter, httpStatus int, e *SunError) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	setRetryAfter(w.Header(), e)
+	w.WriteHeader(httpStatus)
+	return json.NewEncoder(w).Encode(e.ToResponseBody())
+}
+
+// setRetryAfter 将建议重试间隔写入Retry-After响应头, 按秒向上取整
+func setRetryAfter(h http.Header, e *SunError) {
+	retryAfter, ok := e.GetRetryAfter()
+	if !ok {
+		return
+	}
+	seconds := int64((retryAfter + time.Second - 1) / time.Second)
+	h.Set("Retry-After", strconv.FormatInt(seconds, 10))
+}
@@ -0,0 +1,192 @@
+package sunerror
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxErrorBodySize 解析下游错误响应体时最多读取的字节数
+const maxErrorBodySize = 64 << 10
+
+// BodyParser 解析下游HTTP错误响应体, 返回下游错误码与错误信息; ok为false时交给下一个解析器
+type BodyParser func(contentType string, body []byte) (channelCode, channelMsg string, ok bool)
+
+var (
+	parserMu    sync.RWMutex
+	bodyParsers = []BodyParser{parseProblemBody, parseCodeMsgBody}
+)
+
+// RegisterBodyParser 注册自定义的响应体解析器, 优先于内置的problem+json与{code,msg}解析器
+func RegisterBodyParser(p BodyParser) {
+	parserMu.Lock()
+	defer parserMu.Unlock()
+	bodyParsers = append([]BodyParser{p}, bodyParsers...)
+}
+
+// DecodeHTTPError 将下游的HTTP错误响应转换为SunError, 状态码小于400时返回nil
+// 1. 错误码为HTTP_<状态码>, status为状态码, msg为状态码描述, 可通过opts覆盖
+// 2. channelCode/channelMsg取自响应体, 无法解析时为状态码与响应体内容
+// 3. 429/502/503/504视为可重试, detail为请求的method/host/path(不含query)
+// 4. 响应头中的Retry-After(秒数或HTTP日期)记录为建议重试间隔, 见GetRetryAfter
+// 5. 响应体为下游SunError的MarshalJSON结果时, 其fnName与堆栈记录为远端信息, 见WithRemoteOption
+// 读取过的响应体会被放回resp.Body, 调用方仍负责关闭
+func DecodeHTTPError(resp *http.Response, opts ...SunErrOption) *SunError {
+	if resp == nil || resp.StatusCode < http.StatusBadRequest {
+		return nil
+	}
+	ctx := context.Background()
+	if resp.Request != nil {
+		ctx = resp.Request.Context()
+	}
+
+	body := readErrorBody(resp)
+	channelCode, channelMsg := parseErrorBody(resp.Header.Get("Content-Type"), body)
+	if len(channelCode) == 0 {
+		channelCode = strconv.Itoa(resp.StatusCode)
+	}
+	if len(channelMsg) == 0 {
+		channelMsg = strings.TrimSpace(string(body))
+	}
+
+	fields := []SunErrOption{
+		WithSkipDepthOption(1),
+		WithChannelRespOption(channelCode, channelMsg),
+		WithKindOption(httpStatusKind(resp.StatusCode)),
+		WithRetryableOption(httpStatusRetryable(resp.StatusCode)),
+		WithRemoteOption(parseRemoteBody(body)),
+	}
+	if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+		fields = append(fields, WithRetryAfterOption(retryAfter))
+	}
+	if resp.Request != nil && resp.Request.URL != nil {
+		fields = append(fields, WithDetailOption("%s %s%s", resp.Request.Method, resp.Request.URL.Host, resp.Request.URL.Path))
+	}
+	code := Code("HTTP_" + strconv.Itoa(resp.StatusCode))
+	return NewSunError(ctx, code, strconv.Itoa(resp.StatusCode), http.StatusText(resp.StatusCode), append(fields, opts...)...)
+}
+
+type replayBody struct {
+	io.Reader
+	io.Closer
+}
+
+func readErrorBody(resp *http.Response) []byte {
+	if resp.Body == nil {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	resp.Body = replayBody{Reader: io.MultiReader(bytes.NewReader(body), resp.Body), Closer: resp.Body}
+	return body
+}
+
+func parseErrorBody(contentType string, body []byte) (string, string) {
+	if len(body) == 0 {
+		return "", ""
+	}
+	parserMu.RLock()
+	defer parserMu.RUnlock()
+	for _, p := range bodyParsers {
+		if channelCode, channelMsg, ok := p(contentType, body); ok {
+			return channelCode, channelMsg
+		}
+	}
+	return "", ""
+}
+
+// parseProblemBody 解析RFC 7807 problem+json, 优先使用code扩展成员, 否则使用type
+func parseProblemBody(contentType string, body []byte) (string, string, bool) {
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != ProblemContentType {
+		return "", "", false
+	}
+	var problem ProblemDetails
+	if err := json.Unmarshal(body, &problem); err != nil {
+		return "", "", false
+	}
+	channelCode := problem.Code
+	if len(channelCode) == 0 {
+		channelCode = problem.Type
+	}
+	channelMsg := problem.Title
+	if len(problem.Detail) > 0 {
+		channelMsg = channelMsg + ": " + problem.Detail
+	}
+	return channelCode, channelMsg, true
+}
+
+// parseCodeMsgBody 解析常见的{code,msg}格式, 兼容message/errcode/errmsg等字段名及数字错误码
+func parseCodeMsgBody(_ string, body []byte) (string, string, bool) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return "", "", false
+	}
+	channelCode := firstJSONField(fields, "code", "errcode", "error_code", "errorCode")
+	channelMsg := firstJSONField(fields, "msg", "message", "errmsg", "error_msg", "errorMsg", "error")
+	if len(channelCode) == 0 && len(channelMsg) == 0 {
+		return "", "", false
+	}
+	return channelCode, channelMsg, true
+}
+
+func firstJSONField(fields map[string]json.RawMessage, keys ...string) string {
+	for _, key := range keys {
+		raw, ok := fields[key]
+		if !ok {
+			continue
+		}
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			return s
+		}
+		var n json.Number
+		if err := json.Unmarshal(raw, &n); err == nil {
+			return n.String()
+		}
+	}
+	return ""
+}
+
+// parseRetryAfter 解析Retry-After响应头, 值为秒数或HTTP日期, 已过去的日期按0处理
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if len(value) == 0 {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	t, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if d := t.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
+func httpStatusKind(statusCode int) SunErrKind {
+	switch statusCode {
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return TimeoutKind
+	}
+	return DownstreamKind
+}
+
+func httpStatusRetryable(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package sunerror
+
+import (
+	"strings"
+	"sync"
+)
+
+// maxInternLen 参与驻留的字符串的最大长度, 更长的msg通常包含动态内容, 驻留没有收益
+const maxInternLen = 128
+
+var (
+	internMu sync.RWMutex
+	interned = make(map[string]string)
+)
+
+// intern 返回与s内容相同的驻留字符串, 使长期保留的大量错误(去重缓存、异步队列、还原的快照)共享code/status/msg的内存
+// 驻留表最多保存max个字符串, 写满后不再新增; max<=0时不驻留
+func intern(s string, max int) string {
+	if max <= 0 || len(s) == 0 || len(s) > maxInternLen {
+		return s
+	}
+	internMu.RLock()
+	v, ok := interned[s]
+	internMu.RUnlock()
+	if ok {
+		return v
+	}
+	internMu.Lock()
+	defer internMu.Unlock()
+	if v, ok := interned[s]; ok {
+		return v
+	}
+	if len(interned) >= max {
+		return s
+	}
+	// 复制一份, 避免驻留表引用s所在的大块内存(如反序列化的输入)
+	s = strings.Clone(s)
+	interned[s] = s
+	return s
+}
+
+// internFields 驻留code/status/msg与下游错误码
+func (e *SunError) internFields(max int) {
+	if max <= 0 {
+		return
+	}
+	e.code = intern(e.code, max)
+	e.status = intern(e.status, max)
+	e.msg = intern(e.msg, max)
+	e.channelCode = intern(e.channelCode, max)
+}
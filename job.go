@@ -0,0 +1,145 @@
+package sunerror
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// 定时任务包装器构造错误时使用的错误码
+const (
+	// JobPanicCode 任务发生panic
+	JobPanicCode Code = "JOB_PANIC"
+	// JobFailedCode 任务返回了非SunError的错误
+	JobFailedCode Code = "JOB_FAILED"
+	// JobFailedStatus 任务失败的status
+	JobFailedStatus = "FAILED"
+)
+
+type jobConfig struct {
+	schedule    string
+	dedupWindow time.Duration
+	timeout     time.Duration
+	reporter    func(ctx context.Context, sunError *SunError)
+	errOpts     []SunErrOption
+}
+
+// JobOption WrapJob的配置函数
+type JobOption func(c *jobConfig)
+
+// WithJobSchedule 设置任务的调度表达式, 会写入错误的detail
+func WithJobSchedule(spec string) JobOption {
+	return func(c *jobConfig) {
+		c.schedule = spec
+	}
+}
+
+// WithJobDedupWindow 设置告警去重窗口, 窗口内同一错误码只上报一次, 默认10分钟; 传0关闭去重
+func WithJobDedupWindow(window time.Duration) JobOption {
+	return func(c *jobConfig) {
+		c.dedupWindow = window
+	}
+}
+
+// WithJobTimeout 设置单次执行的超时时间, 默认不超时
+func WithJobTimeout(timeout time.Duration) JobOption {
+	return func(c *jobConfig) {
+		c.timeout = timeout
+	}
+}
+
+// WithJobReporter 设置任务失败的上报函数(如发送告警), 受去重窗口限制
+func WithJobReporter(reporter func(ctx context.Context, sunError *SunError)) JobOption {
+	return func(c *jobConfig) {
+		c.reporter = reporter
+	}
+}
+
+// WithJobErrorOptions 设置包装器构造SunError时使用的选项, 如WithLogEngine
+func WithJobErrorOptions(opts ...SunErrOption) JobOption {
+	return func(c *jobConfig) {
+		c.errOpts = append(c.errOpts, opts...)
+	}
+}
+
+// WrapJob 包装robfig/cron风格的定时任务, 返回值可直接传给cron.AddFunc
+// 1. recover任务中的panic, 转换为JobPanicCode的SunError
+// 2. 失败的SunError在detail中追加任务名与调度表达式, 非SunError转换为JobFailedCode的SunError
+// 3. 同一错误码在去重窗口内只上报一次, 下次上报时在detail中附带被抑制的次数
+func WrapJob(name string, fn func(ctx context.Context) error, opts ...JobOption) func() {
+	c := &jobConfig{dedupWindow: 10 * time.Minute}
+	for _, opt := range opts {
+		opt(c)
+	}
+	dedup := &jobDedup{window: c.dedupWindow, seen: make(map[string]*jobSeen)}
+
+	return func() {
+		ctx := context.Background()
+		if c.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+		}
+		sunErr := c.run(ctx, name, fn)
+		if sunErr == nil || c.reporter == nil {
+			return
+		}
+		if suppressed, ok := dedup.allow(sunErr.code, time.Now()); ok {
+			if suppressed > 0 {
+				sunErr = sunErr.AppendDetail("suppressed=%d", suppressed)
+			}
+			c.reporter(ctx, sunErr)
+		}
+	}
+}
+
+func (c *jobConfig) run(ctx context.Context, name string, fn func(ctx context.Context) error) (sunErr *SunError) {
+	defer func() {
+		if r := recover(); r != nil {
+			fields := []SunErrOption{WithDetailOption("job=%s schedule=%s panic=%v", name, c.schedule, r), WithStackRows(32)}
+			sunErr = NewSunError(ctx, JobPanicCode, JobFailedStatus, "job panic", append(fields, c.errOpts...)...)
+		}
+	}()
+	err := fn(ctx)
+	if err == nil {
+		return nil
+	}
+	if errors.As(err, &sunErr) {
+		return sunErr.AppendDetail("job=%s schedule=%s", name, c.schedule)
+	}
+	fields := []SunErrOption{WithDetailOption("job=%s schedule=%s", name, c.schedule), WithStackOption(false)}
+	return NewSunError(ctx, JobFailedCode, JobFailedStatus, err.Error(), append(fields, c.errOpts...)...)
+}
+
+type jobSeen struct {
+	last       time.Time
+	suppressed int
+}
+
+type jobDedup struct {
+	mu     sync.Mutex
+	window time.Duration
+	seen   map[string]*jobSeen
+}
+
+// allow 判断本次是否上报, 返回上次上报后被抑制的次数
+func (d *jobDedup) allow(code string, now time.Time) (int, bool) {
+	if d.window <= 0 {
+		return 0, true
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	s, ok := d.seen[code]
+	if !ok {
+		d.seen[code] = &jobSeen{last: now}
+		return 0, true
+	}
+	if now.Sub(s.last) < d.window {
+		s.suppressed++
+		return 0, false
+	}
+	suppressed := s.suppressed
+	s.last, s.suppressed = now, 0
+	return suppressed, true
+}
@@ -0,0 +1,161 @@
+package sunerror
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Record SunError可以跨进程传递的全部字段, 是JSON/文本/gob等序列化格式共用的结构
+// 自定义序列化格式(如protobuf)通过ToRecord/FromRecord与SunError互相转换, 不需要访问未导出的字段
+type Record struct {
+	Code        string           `json:"code"`
+	Status      string           `json:"status"`
+	Msg         string           `json:"msg"`
+	Detail      string           `json:"detail,omitempty"`
+	FnName      string           `json:"fnName,omitempty"`
+	ChannelCode string           `json:"channelCode,omitempty"`
+	ChannelMsg  string           `json:"channelMsg,omitempty"`
+	ErrorID     string           `json:"errorID,omitempty"`
+	TraceID     string           `json:"traceID,omitempty"`
+	UserMsg     string           `json:"userMsg,omitempty"`
+	DocsURL     string           `json:"docsURL,omitempty"`
+	Kind        string           `json:"kind,omitempty"`
+	Retryable   bool             `json:"retryable,omitempty"`
+	RetryAfter  string           `json:"retryAfter,omitempty"` // time.Duration.String()的格式, 为空表示未设置
+	SideEffect  bool             `json:"sideEffect,omitempty"`
+	Level       SunErrLevel      `json:"level"`
+	Cause       string           `json:"cause,omitempty"`
+	Violations  []FieldViolation `json:"violations,omitempty"`
+	Request     string           `json:"request,omitempty"`  // WithPayloadCaptureOption保存的请求
+	Response    string           `json:"response,omitempty"` // WithPayloadCaptureOption保存的响应
+	// WithChannelCallInfo记录的下游调用信息, ChannelLatency为time.Duration.String()的格式, 为空表示未设置
+	ChannelEndpoint string `json:"channelEndpoint,omitempty"`
+	ChannelLatency  string `json:"channelLatency,omitempty"`
+	ChannelAttempt  int    `json:"channelAttempt,omitempty"`
+	Stack           string `json:"stack,omitempty"`
+	// WithRemoteOption记录的远端调用点与堆栈, 与本地的FnName/Stack分开
+	RemoteFnName string `json:"remoteFnName,omitempty"`
+	RemoteStack  string `json:"remoteStack,omitempty"`
+}
+
+// MarshalJSON 序列化全部可跨进程传递的字段, 包括fnName与堆栈, 用于结构化日志与错误存档
+// 对外响应请使用ToResponseBody, 避免泄露函数名与堆栈
+func (e *SunError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.ToRecord(true))
+}
+
+// ToRecord 转换为Record, withStack为false时不包含堆栈与请求/响应负载
+func (e *SunError) ToRecord(withStack bool) Record {
+	r := Record{
+		Code:        e.code,
+		Status:      e.status,
+		Msg:         e.msg,
+		Detail:      e.GetDetail(),
+		FnName:      e.GetFnName(),
+		ChannelCode: e.channelCode,
+		ChannelMsg:  e.GetChannelMsg(),
+		ErrorID:     e.GetErrorID(),
+		TraceID:     e.traceID,
+		UserMsg:     e.userMsg,
+		DocsURL:     e.docsURL,
+		Retryable:   e.retryable,
+		SideEffect:  e.sideEffect,
+		Kind:        kindName(e.kind),
+		Level:       e.level,
+		Violations:  e.violations,
+	}
+	if e.cause != nil {
+		r.Cause = e.causeText()
+	}
+	if e.hasRetryAfter {
+		r.RetryAfter = e.retryAfter.String()
+	}
+	if c := e.channelCall; c != nil {
+		r.ChannelEndpoint, r.ChannelLatency, r.ChannelAttempt = c.endpoint, c.latency.String(), c.attempt
+	}
+	if withStack && e.storeStack {
+		r.Stack = string(e.stack)
+	}
+	if rs := e.remote; rs != nil {
+		r.RemoteFnName = rs.fnName
+		if withStack {
+			r.RemoteStack = rs.stack
+		}
+	}
+	if withStack {
+		r.Request, r.Response = e.GetPayload()
+	}
+	return r
+}
+
+// UnmarshalJSON 从MarshalJSON的结果还原, 还原时不打印日志也不执行执行器
+// 原始错误只能还原为内容相同的errors.New, 不再能被errors.Is匹配
+func (e *SunError) UnmarshalJSON(data []byte) error {
+	var r Record
+	if err := json.Unmarshal(data, &r); err != nil {
+		return err
+	}
+	return e.setRecord(r)
+}
+
+// FromRecord 从Record还原SunError, 与UnmarshalJSON一样不打印日志也不执行执行器
+func FromRecord(r Record) (*SunError, error) {
+	e := newSunError()
+	cache := e.errCache
+	if err := e.setRecord(r); err != nil {
+		return nil, err
+	}
+	e.errCache = cache
+	return e, nil
+}
+
+func (e *SunError) setRecord(r Record) error {
+	kind, _ := ParseKind(r.Kind)
+	*e = SunError{
+		code:        r.Code,
+		status:      r.Status,
+		msg:         r.Msg,
+		level:       r.Level,
+		detail:      r.Detail,
+		fnName:      r.FnName,
+		channelCode: r.ChannelCode,
+		channelMsg:  r.ChannelMsg,
+		errorID:     r.ErrorID,
+		traceID:     r.TraceID,
+		userMsg:     r.UserMsg,
+		docsURL:     r.DocsURL,
+		kind:        kind,
+		retryable:   r.Retryable,
+		sideEffect:  r.SideEffect,
+		violations:  r.Violations,
+		noLog:       true,
+		depth:       2,
+		stackRows:   10,
+	}
+	if len(r.Cause) > 0 {
+		e.cause = errors.New(r.Cause)
+	}
+	e.payload = restoredPayload(r.Request, r.Response)
+	e.internFields(loadConfig().InternStrings)
+	if len(r.RetryAfter) > 0 {
+		retryAfter, err := time.ParseDuration(r.RetryAfter)
+		if err != nil {
+			return err
+		}
+		e.retryAfter, e.hasRetryAfter = retryAfter, true
+	}
+	if len(r.ChannelLatency) > 0 {
+		latency, err := time.ParseDuration(r.ChannelLatency)
+		if err != nil {
+			return err
+		}
+		e.channelCall = &channelCall{endpoint: r.ChannelEndpoint, latency: latency, attempt: r.ChannelAttempt}
+	}
+	if len(r.Stack) > 0 {
+		e.storeStack = true
+		e.stack = []byte(r.Stack)
+	}
+	WithRemoteOption(r.RemoteFnName, r.RemoteStack)(e)
+	return nil
+}
@@ -0,0 +1,76 @@
+package sunerror
+
+// SunErrKind 错误分类, 用于重试/熔断/状态码映射等策略判断
+type SunErrKind int8
+
+const (
+	// UnknownKind 未分类
+	UnknownKind SunErrKind = iota
+	// ValidationKind 参数校验失败
+	ValidationKind
+	// UnauthenticatedKind 未认证
+	UnauthenticatedKind
+	// PermissionDeniedKind 无权限
+	PermissionDeniedKind
+	// NotFoundKind 资源不存在
+	NotFoundKind
+	// ConflictKind 资源冲突, 如重复提交
+	ConflictKind
+	// DownstreamKind 下游服务异常
+	DownstreamKind
+	// TimeoutKind 超时
+	TimeoutKind
+	// TransientKind 临时性故障, 如网络抖动
+	TransientKind
+	// InternalKind 服务内部错误
+	InternalKind
+)
+
+var kindNames = [...]string{
+	UnknownKind:          "unknown",
+	ValidationKind:       "validation",
+	UnauthenticatedKind:  "unauthenticated",
+	PermissionDeniedKind: "permission_denied",
+	NotFoundKind:         "not_found",
+	ConflictKind:         "conflict",
+	DownstreamKind:       "downstream",
+	TimeoutKind:          "timeout",
+	TransientKind:        "transient",
+	InternalKind:         "internal",
+}
+
+func (k SunErrKind) String() string {
+	if k >= 0 && int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return kindNames[UnknownKind]
+}
+
+// ParseKind 按String()的结果解析错误分类, 无法识别时返回UnknownKind与false
+func ParseKind(name string) (SunErrKind, bool) {
+	for k, n := range kindNames {
+		if n == name {
+			return SunErrKind(k), true
+		}
+	}
+	return UnknownKind, false
+}
+
+// kindName 序列化时使用的分类名, UnknownKind为空字符串以便omitempty省略
+func kindName(k SunErrKind) string {
+	if k == UnknownKind {
+		return ""
+	}
+	return k.String()
+}
+
+// WithKindOption 设置错误分类, 不设置时默认为UnknownKind
+func WithKindOption(kind SunErrKind) SunErrOption {
+	return func(e *SunError) {
+		e.kind = kind
+	}
+}
+
+func (e *SunError) GetKind() SunErrKind {
+	return e.kind
+}
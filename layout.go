@@ -0,0 +1,257 @@
+package sunerror
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// DefaultLayout 与默认的Error()格式相同的布局模板
+const DefaultLayout = "[{fnName}] code={code}, msg={msg}, channelCode={channelCode}, channelMsg={channelMsg}, detail={detail}, errorID={errorID}" +
+	"{?, channelEndpoint={channelEndpoint}, channelLatency={channelLatency}, channelAttempt={channelAttempt}}{?, cause={cause}}{?\n{stack}}{?\n{remote}}"
+
+// layoutFields 布局模板中可以使用的字段
+var layoutFields = map[string]func(e *SunError) string{
+	"fnName":          (*SunError).GetFnName,
+	"code":            func(e *SunError) string { return e.code },
+	"status":          (*SunError).GetStatus,
+	"msg":             (*SunError).GetMsg,
+	"level":           func(e *SunError) string { return e.level.String() },
+	"detail":          (*SunError).GetDetail,
+	"channelCode":     (*SunError).GetChannelCode,
+	"channelMsg":      (*SunError).GetChannelMsg,
+	"channelEndpoint": func(e *SunError) string { endpoint, _, _, _ := e.GetChannelCallInfo(); return endpoint },
+	"channelLatency": func(e *SunError) string {
+		if _, latency, _, ok := e.GetChannelCallInfo(); ok {
+			return latency.String()
+		}
+		return ""
+	},
+	"channelAttempt": func(e *SunError) string {
+		if _, _, attempt, ok := e.GetChannelCallInfo(); ok {
+			return strconv.Itoa(attempt)
+		}
+		return ""
+	},
+	"errorID": (*SunError).GetErrorID,
+	"traceID": (*SunError).GetTraceID,
+	"groupID": (*SunError).GetGroupID,
+	"tenant":  (*SunError).GetTenant,
+	"kind":    func(e *SunError) string { return kindName(e.kind) },
+	"retryable": func(e *SunError) string {
+		if e.retryable {
+			return "true"
+		}
+		return ""
+	},
+	"userMsg": func(e *SunError) string { return e.userMsg },
+	"docsURL": func(e *SunError) string { return e.docsURL },
+	"cause": func(e *SunError) string {
+		if e.cause == nil {
+			return ""
+		}
+		return e.causeText()
+	},
+	"stack":        func(e *SunError) string { return e.FormatStack(nil) },
+	"remoteFnName": func(e *SunError) string { fnName, _, _ := e.GetRemote(); return fnName },
+	"remoteStack":  func(e *SunError) string { _, stack, _ := e.GetRemote(); return stack },
+	"remote":       (*SunError).remoteText,
+}
+
+type layoutNode struct {
+	lit      string
+	field    func(e *SunError) string
+	stack    bool // {stack}, 按Layout的StackFormatter输出
+	optional []layoutNode
+}
+
+// Layout Error()文本的布局, 由ParseLayout解析模板得到, 可以并发使用
+type Layout struct {
+	src   string
+	nodes []layoutNode
+	stack StackFormatter
+}
+
+// ParseLayout 解析布局模板, 模板中的字段与分隔符决定输出的字段、顺序与格式
+// 1. {name}输出字段, 可用的字段: fnName code status msg level detail channelCode channelMsg channelEndpoint channelLatency
+// channelAttempt errorID traceID groupID tenant kind retryable userMsg docsURL cause stack remoteFnName remoteStack,
+// 以及remote(远端的边界标记行与堆栈, 见WithRemoteOption)
+// 2. {?...}为可选段, 其中任意字段为空时整段不输出, 如"{?, cause={cause}}"、"{?\n{stack}}"
+// 3. {{与}}输出字面的{与}
+// 4. 除fnName、stack、remoteStack与remote外, 字段值与Error()一样在包含分隔符、引号或控制字符时加引号并转义
+//
+//	layout := sunerror.MustParseLayout("{level}|{code}|{msg}|{errorID}{?|{detail}}")
+func ParseLayout(tmpl string) (*Layout, error) {
+	nodes, _, err := parseLayout(tmpl, false)
+	if err != nil {
+		return nil, err
+	}
+	return &Layout{src: tmpl, nodes: nodes}, nil
+}
+
+// MustParseLayout 同ParseLayout, 模板不合法时panic, 用于包级变量
+func MustParseLayout(tmpl string) *Layout {
+	l, err := ParseLayout(tmpl)
+	if err != nil {
+		panic(err)
+	}
+	return l
+}
+
+func parseLayout(s string, inOptional bool) ([]layoutNode, string, error) {
+	var nodes []layoutNode
+	var lit strings.Builder
+	flush := func() {
+		if lit.Len() > 0 {
+			nodes = append(nodes, layoutNode{lit: lit.String()})
+			lit.Reset()
+		}
+	}
+	for len(s) > 0 {
+		switch {
+		case strings.HasPrefix(s, "{{"):
+			lit.WriteByte('{')
+			s = s[2:]
+		case strings.HasPrefix(s, "}}"):
+			lit.WriteByte('}')
+			s = s[2:]
+		case s[0] == '}':
+			if !inOptional {
+				return nil, "", errors.New("sunerror: unmatched } in layout")
+			}
+			flush()
+			return nodes, s[1:], nil
+		case strings.HasPrefix(s, "{?"):
+			flush()
+			sub, rest, err := parseLayout(s[2:], true)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, layoutNode{optional: sub})
+			s = rest
+		case s[0] == '{':
+			end := strings.IndexByte(s, '}')
+			if end < 0 {
+				return nil, "", errors.New("sunerror: unclosed { in layout")
+			}
+			name := s[1:end]
+			field, ok := layoutFields[name]
+			if !ok {
+				return nil, "", errors.New("sunerror: unknown layout field " + strconv.Quote(name))
+			}
+			flush()
+			switch name {
+			case "stack":
+				nodes = append(nodes, layoutNode{stack: true})
+			case "fnName", "remoteStack", "remote":
+				nodes = append(nodes, layoutNode{field: field})
+			default:
+				raw := field
+				nodes = append(nodes, layoutNode{field: func(e *SunError) string { return errorValue(raw(e)) }})
+			}
+			s = s[end+1:]
+		default:
+			lit.WriteByte(s[0])
+			s = s[1:]
+		}
+	}
+	if inOptional {
+		return nil, "", errors.New("sunerror: unclosed {? in layout")
+	}
+	flush()
+	return nodes, "", nil
+}
+
+// String 返回布局的模板
+func (l *Layout) String() string {
+	return l.src
+}
+
+// WithStackFormatter 返回{stack}按f格式化的布局副本, 不影响原布局与其他输出目标, 如人看的日志用TextStack而采集到APM的日志用JSONStack
+func (l *Layout) WithStackFormatter(f StackFormatter) *Layout {
+	out := *l
+	out.stack = f
+	return &out
+}
+
+// Format 按布局格式化错误
+func (l *Layout) Format(e *SunError) string {
+	return string(l.Append(make([]byte, 0, 256), e))
+}
+
+// Append 按布局将错误追加到dst并返回
+func (l *Layout) Append(dst []byte, e *SunError) []byte {
+	dst, _ = l.appendNodes(dst, l.nodes, e)
+	return dst
+}
+
+// appendNodes 返回追加后的dst与其中的字段是否都不为空
+func (l *Layout) appendNodes(dst []byte, nodes []layoutNode, e *SunError) ([]byte, bool) {
+	full := true
+	for _, n := range nodes {
+		switch {
+		case n.field != nil || n.stack:
+			var v string
+			if n.stack {
+				v = e.FormatStack(l.stack)
+			} else {
+				v = n.field(e)
+			}
+			full = full && len(v) > 0
+			dst = append(dst, v...)
+		case n.optional != nil:
+			mark := len(dst)
+			var ok bool
+			if dst, ok = l.appendNodes(dst, n.optional, e); !ok {
+				dst = dst[:mark]
+			}
+		default:
+			dst = append(dst, n.lit...)
+		}
+	}
+	return dst, full
+}
+
+// errorLayout SetErrorLayout设置的全局布局, 未设置时为nil
+var errorLayout atomic.Value
+
+// SetErrorLayout 设置Error()使用的全局布局, 传nil时恢复默认格式
+// 对之后首次调用Error()的错误生效, 已经缓存了Error()结果的错误不受影响
+func SetErrorLayout(l *Layout) {
+	errorLayout.Store(&l)
+}
+
+func loadErrorLayout() *Layout {
+	p, _ := errorLayout.Load().(**Layout)
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+// WithLayoutEngine 包装日志引擎, 日志参数中的SunError按layout格式化后再交给log, 不影响Error()与其他引擎
+// 不同团队的日志解析器要求不同格式时, 可以为每个日志引擎单独设置布局
+func WithLayoutEngine(log func(ctx context.Context, format string, v ...interface{}), layout *Layout) func(ctx context.Context, format string, v ...interface{}) {
+	return func(ctx context.Context, format string, v ...interface{}) {
+		log(ctx, format, layoutArgs(layout, v)...)
+	}
+}
+
+// layoutArgs 将日志参数中的SunError替换为按layout格式化的文本
+func layoutArgs(layout *Layout, v []interface{}) []interface{} {
+	if layout == nil {
+		return v
+	}
+	out, copied := v, false
+	for i, arg := range v {
+		if e, ok := arg.(*SunError); ok {
+			if !copied {
+				out, copied = append([]interface{}(nil), v...), true
+			}
+			out[i] = layout.Format(e)
+		}
+	}
+	return out
+}
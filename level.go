@@ -0,0 +1,92 @@
+package sunerror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// UnknownLevel LevelOf对nil与非SunError返回的等级, 低于所有等级
+const UnknownLevel SunErrLevel = math.MinInt8
+
+var levelNames = map[SunErrLevel]string{
+	DebugLevel: "debug",
+	InfoLevel:  "info",
+	WarnLevel:  "warn",
+	ErrorLevel: "error",
+	FatalLevel: "fatal",
+}
+
+func (l SunErrLevel) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	if l == UnknownLevel {
+		return "unknown"
+	}
+	return "level(" + strconv.Itoa(int(l)) + ")"
+}
+
+// ParseLevel 按String()的结果解析错误等级, 不区分大小写, 也接受warning与数字形式(兼容旧版本的序列化结果)
+func ParseLevel(name string) (SunErrLevel, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "warning" {
+		return WarnLevel, true
+	}
+	for l, n := range levelNames {
+		if n == name {
+			return l, true
+		}
+	}
+	if n, err := strconv.ParseInt(name, 10, 8); err == nil {
+		return SunErrLevel(n), true
+	}
+	return ErrorLevel, false
+}
+
+// MarshalText 以String()的名称序列化, 配置文件与JSON中的等级为可读的名称
+func (l SunErrLevel) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText 按ParseLevel解析
+func (l *SunErrLevel) UnmarshalText(text []byte) error {
+	level, ok := ParseLevel(string(text))
+	if !ok {
+		return fmt.Errorf("sunerror: unknown level %q", text)
+	}
+	*l = level
+	return nil
+}
+
+// UnmarshalJSON 接受名称与旧版本序列化的数字
+func (l *SunErrLevel) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		return l.UnmarshalText([]byte(name))
+	}
+	var n int8
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("sunerror: invalid level %s", data)
+	}
+	*l = SunErrLevel(n)
+	return nil
+}
+
+// IsAtLeast 错误等级是否不低于level, 如e.IsAtLeast(sunerror.ErrorLevel)
+func (e *SunError) IsAtLeast(level SunErrLevel) bool {
+	return e.level >= level
+}
+
+// LevelOf 返回err链中SunError的等级, err为nil或不是SunError时返回UnknownLevel
+// 供中间件按严重程度分支处理, 如只对ErrorLevel及以上的错误告警
+func LevelOf(err error) SunErrLevel {
+	var sunErr *SunError
+	if err == nil || !errors.As(err, &sunErr) {
+		return UnknownLevel
+	}
+	return sunErr.level
+}
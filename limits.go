@@ -0,0 +1,149 @@
+package sunerror
+
+import (
+	"bytes"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Limits 错误内容的大小上限, 防止调用方把整个请求体放入detail拖垮日志管道与下游存储; 零值字段表示不限制
+// 超出上限的内容被截断并追加截断标记, 如"...(truncated 1024 bytes)"
+type Limits struct {
+	MaxDetail     int // detail的最大字节数
+	MaxFieldValue int // channelMsg、userMsg、原始错误、单条校验失败说明等字段的最大字节数
+	MaxViolations int // 校验失败明细的最大条数, 超出的部分丢弃并追加一条截断说明
+	MaxPayload    int // WithPayloadCaptureOption保存的请求/响应序列化后的最大字节数
+	// MaxTotal 整个错误记录的最大字节数, 超出时先截断堆栈(按整行保留栈顶), 仍超出时再截断detail
+	// 按字段内容计算, 不含JSON键名等编码开销, 负载由MaxPayload单独限制; 用于对齐下游的消息大小上限(如Kafka 1MB、SQS 256KB), 应留出余量
+	// 设置后延迟格式化的detail与原始错误的文本在构造时计算
+	MaxTotal int
+}
+
+// applyLimits 构造时截断已确定的字段, 延迟格式化的detail在格式化时截断
+func (e *SunError) applyLimits(l Limits) {
+	if l.MaxDetail > 0 {
+		e.detail = truncate(e.detail, l.MaxDetail)
+		if e.lazyDetail != nil {
+			e.lazyDetail.max = l.MaxDetail
+		}
+	}
+	if l.MaxFieldValue > 0 {
+		e.channelMsg = truncate(e.channelMsg, l.MaxFieldValue)
+		e.userMsg = truncate(e.userMsg, l.MaxFieldValue)
+		copied := false
+		for i := range e.violations {
+			if len(e.violations[i].Message) <= l.MaxFieldValue {
+				continue
+			}
+			if !copied {
+				e.violations = append([]FieldViolation(nil), e.violations...)
+				copied = true
+			}
+			e.violations[i].Message = truncate(e.violations[i].Message, l.MaxFieldValue)
+		}
+	}
+	if l.MaxViolations > 0 && len(e.violations) > l.MaxViolations {
+		dropped := len(e.violations) - l.MaxViolations
+		violations := make([]FieldViolation, 0, l.MaxViolations+1)
+		violations = append(violations, e.violations[:l.MaxViolations]...)
+		e.violations = append(violations, FieldViolation{Field: "...", Message: "truncated " + strconv.Itoa(dropped) + " violations"})
+	}
+	if l.MaxTotal > 0 {
+		e.applySizeBudget(l.MaxTotal)
+	}
+}
+
+// StackSize 保存的堆栈的字节数, 未保存堆栈时为0
+func (e *SunError) StackSize() int {
+	if !e.storeStack {
+		return 0
+	}
+	return len(e.stack)
+}
+
+// applySizeBudget 记录超出max字节时先截断堆栈, 再截断detail
+func (e *SunError) applySizeBudget(max int) {
+	detail := e.rawDetail()
+	stack := e.StackSize()
+	over := e.fieldsSize() + len(detail) + stack - max
+	if over <= 0 {
+		return
+	}
+	if stack > 0 {
+		e.stack = truncateStack(e.stack, stack-over)
+		over -= stack - len(e.stack)
+	}
+	if over > 0 && len(detail) > 0 {
+		e.detail = truncateTo(detail, len(detail)-over)
+		e.lazyDetail = nil
+	}
+}
+
+// fieldsSize 除detail与堆栈外各字段的字节数
+func (e *SunError) fieldsSize() int {
+	n := len(e.code) + len(e.status) + len(e.msg) + len(e.GetFnName()) + len(e.channelCode) + len(e.channelMsg) +
+		len(e.GetErrorID()) + len(e.traceID) + len(e.groupID) + len(e.tenant) + len(e.userMsg) + len(e.docsURL)
+	for _, v := range e.violations {
+		n += len(v.Field) + len(v.Message)
+	}
+	if c := e.channelCall; c != nil {
+		n += len(c.endpoint)
+	}
+	if r := e.remote; r != nil {
+		n += len(r.fnName) + len(r.stack)
+	}
+	if e.cause != nil {
+		n += len(e.causeText())
+	}
+	return n
+}
+
+// truncateStack 按整行保留栈顶, 使包括截断标记行在内不超过max字节, 返回新分配的切片
+func truncateStack(stack []byte, max int) []byte {
+	marker := "...(truncated " + strconv.Itoa(len(stack)) + " bytes)\n"
+	keep := 0
+	for keep < len(stack) {
+		i := bytes.IndexByte(stack[keep:], '\n')
+		if i < 0 || keep+i+1+len(marker) > max {
+			break
+		}
+		keep += i + 1
+	}
+	out := make([]byte, 0, keep+len(marker))
+	out = append(out, stack[:keep]...)
+	out = append(out, "...(truncated "...)
+	out = strconv.AppendInt(out, int64(len(stack)-keep), 10)
+	return append(out, " bytes)\n"...)
+}
+
+// truncateTo 与truncate相同, 但截断标记也计入max, max不足以容纳标记时只保留标记
+func truncateTo(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	n := max - len("...(truncated "+strconv.Itoa(len(s))+" bytes)")
+	if n < 0 {
+		n = 0
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "...(truncated " + strconv.Itoa(len(s)-n) + " bytes)"
+}
+
+// causeText 原始错误的文本, 按MaxFieldValue截断
+func (e *SunError) causeText() string {
+	return truncate(e.cause.Error(), loadConfig().Limits.MaxFieldValue)
+}
+
+// truncate 将s截断到不超过max字节(不截断UTF-8字符)并追加截断标记, max<=0或未超出时原样返回
+func truncate(s string, max int) string {
+	if max <= 0 || len(s) <= max {
+		return s
+	}
+	n := max
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "...(truncated " + strconv.Itoa(len(s)-n) + " bytes)"
+}
@@ -0,0 +1,79 @@
+package sunerror
+
+import (
+	"strconv"
+	"unicode/utf8"
+)
+
+// AppendLogfmt 将错误以logfmt格式(key=value, 空格分隔)追加到dst并返回, 整条记录保持在一行内
+// 依次输出level、code、status、msg, 其余字段(detail、fnName、下游信息与调用信息、errorID、traceID、kind、retryable、cause、stack、远端的fnName与堆栈)为空时省略
+// 值包含空格、=、引号或控制字符时加引号并转义, 堆栈中的换行转义为\n
+func (e *SunError) AppendLogfmt(dst []byte) []byte {
+	dst = appendLogfmtPair(dst, "level", e.level.String())
+	dst = appendLogfmtPair(dst, "code", e.code)
+	dst = appendLogfmtPair(dst, "status", e.status)
+	dst = appendLogfmtPair(dst, "msg", e.msg)
+	dst = appendLogfmtOptional(dst, "detail", e.GetDetail())
+	dst = appendLogfmtOptional(dst, "fnName", e.GetFnName())
+	dst = appendLogfmtOptional(dst, "channelCode", e.channelCode)
+	dst = appendLogfmtOptional(dst, "channelMsg", e.GetChannelMsg())
+	if c := e.channelCall; c != nil {
+		dst = appendLogfmtOptional(dst, "channelEndpoint", c.endpoint)
+		dst = appendLogfmtPair(dst, "channelLatency", c.latency.String())
+		dst = appendLogfmtPair(dst, "channelAttempt", strconv.Itoa(c.attempt))
+	}
+	dst = appendLogfmtOptional(dst, "errorID", e.GetErrorID())
+	dst = appendLogfmtOptional(dst, "traceID", e.traceID)
+	dst = appendLogfmtOptional(dst, "kind", kindName(e.kind))
+	if e.retryable {
+		dst = appendLogfmtPair(dst, "retryable", "true")
+	}
+	if e.cause != nil {
+		dst = appendLogfmtPair(dst, "cause", e.causeText())
+	}
+	if e.storeStack && len(e.stack) > 0 {
+		dst = appendLogfmtPair(dst, "stack", string(e.stack))
+	}
+	if r := e.remote; r != nil {
+		dst = appendLogfmtOptional(dst, "remoteFnName", r.fnName)
+		dst = appendLogfmtOptional(dst, "remoteStack", r.stack)
+	}
+	return dst
+}
+
+// Logfmt 返回logfmt格式的错误, 见AppendLogfmt
+func (e *SunError) Logfmt() string {
+	return string(e.AppendLogfmt(make([]byte, 0, 256)))
+}
+
+func appendLogfmtOptional(dst []byte, key, value string) []byte {
+	if len(value) == 0 {
+		return dst
+	}
+	return appendLogfmtPair(dst, key, value)
+}
+
+func appendLogfmtPair(dst []byte, key, value string) []byte {
+	if len(dst) > 0 {
+		dst = append(dst, ' ')
+	}
+	dst = append(dst, key...)
+	dst = append(dst, '=')
+	if !logfmtNeedsQuote(value) {
+		return append(dst, value...)
+	}
+	return strconv.AppendQuote(dst, value)
+}
+
+// logfmtNeedsQuote 空值及包含空格、=、引号、控制字符、不可打印字符(如U+0085、U+2028)或非法UTF-8的值需要加引号
+func logfmtNeedsQuote(value string) bool {
+	if len(value) == 0 {
+		return true
+	}
+	for _, r := range value {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == utf8.RuneError || !strconv.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
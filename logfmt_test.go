@@ -0,0 +1,59 @@
+package sunerror
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// parseLogfmt 解析空格分隔的key=value, 带引号的值按Go语法还原
+func parseLogfmt(t *testing.T, line string) map[string]string {
+	t.Helper()
+	fields := make(map[string]string)
+	for len(line) > 0 {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			t.Fatalf("missing '=' in %q", line)
+		}
+		if strings.HasPrefix(value, `"`) {
+			quoted, err := strconv.QuotedPrefix(value)
+			if err != nil {
+				t.Fatalf("bad quoted value %q: %v", value, err)
+			}
+			fields[key], _ = strconv.Unquote(quoted)
+			line = value[len(quoted):]
+		} else {
+			end := strings.IndexByte(value, ' ')
+			if end < 0 {
+				end = len(value)
+			}
+			fields[key] = value[:end]
+			line = value[end:]
+		}
+		line = strings.TrimPrefix(line, " ")
+	}
+	return fields
+}
+
+func TestLogfmtQuotesAdversarialValues(t *testing.T) {
+	ctx := context.Background()
+	for _, v := range adversarialValues {
+		inner := NewSunError(ctx, "LOGFMT_INNER", "500", v, WithNoLogOption(), WithStackOption(false))
+		e := Wrap(ctx, inner, "LOGFMT_1", "500", v, WithNoLogOption(), WithDetailOption("%s", v))
+		line := e.Logfmt()
+		if strings.ContainsAny(line, "\n\r\u2028\u2029\u0085") {
+			t.Fatalf("logfmt spans lines for %q: %q", v, line)
+		}
+		fields := parseLogfmt(t, line)
+		if fields["msg"] != v || fields["detail"] != v {
+			t.Fatalf("msg = %q, detail = %q, want %q\nlogfmt = %q", fields["msg"], fields["detail"], v, line)
+		}
+		if fields["cause"] != inner.Error() {
+			t.Fatalf("cause = %q, want %q", fields["cause"], inner.Error())
+		}
+		if fields["stack"] != string(e.stack) {
+			t.Fatalf("stack not restored from %q", line)
+		}
+	}
+}
@@ -0,0 +1,5 @@
+//go:build !race
+
+package sunerror
+
+const raceEnabled = false
@@ -0,0 +1,92 @@
+package sunerror
+
+import (
+	"context"
+	"log"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	defaultPanicStackSize = 3000
+	maxPanicStackSize     = 1 << 20
+)
+
+// PanicHandler 处理异步执行器等内部执行过程中recover到的panic, stack为发生panic的协程堆栈
+// stack所在的缓冲区会被复用, 需要在handler返回后继续使用时请自行复制
+type PanicHandler func(ctx context.Context, recovered interface{}, stack []byte)
+
+var (
+	panicMu      sync.RWMutex
+	panicHandler PanicHandler
+
+	panicStackSize int64 = defaultPanicStackSize
+)
+
+// SetPanicHandler 设置全局panic处理函数, 所有内部recover都会交给它处理; 传nil恢复默认处理
+// 默认处理: 错误设置了日志引擎时通过日志引擎打印, 否则通过标准库log打印, 不会因为未设置日志引擎再次panic
+func SetPanicHandler(handler PanicHandler) {
+	panicMu.Lock()
+	defer panicMu.Unlock()
+	panicHandler = handler
+}
+
+func (e *SunError) handlePanic(ctx context.Context, recovered interface{}, stack []byte) {
+	handlePanic(ctx, recovered, stack, e.logEngine)
+}
+
+// handlePanic 交给全局panic处理函数, 未设置时通过logEngine打印, logEngine为nil时通过标准库log打印
+func handlePanic(ctx context.Context, recovered interface{}, stack []byte, logEngine logFunc) {
+	panicMu.RLock()
+	handler := panicHandler
+	panicMu.RUnlock()
+	if handler != nil {
+		handler(ctx, recovered, stack)
+		return
+	}
+	if logEngine != nil {
+		logEngine(ctx, "SafeGo has panic: %v\n%s", recovered, stack)
+		return
+	}
+	log.Printf("sunerror: SafeGo has panic: %v\n%s", recovered, stack)
+}
+
+// SetPanicStackSize 设置recover时抓取堆栈的初始缓冲区大小, 默认3000字节, n<=0时恢复默认值
+// 堆栈超出缓冲区时会成倍扩容后重新抓取, 最大1MB, 与标准库http server的做法一致
+func SetPanicStackSize(n int) {
+	if n <= 0 {
+		n = defaultPanicStackSize
+	}
+	if n > maxPanicStackSize {
+		n = maxPanicStackSize
+	}
+	atomic.StoreInt64(&panicStackSize, int64(n))
+}
+
+// panicStack 从对象池取缓冲区抓取当前协程的堆栈, 被截断时扩容重试; 返回的切片长度即堆栈长度
+func panicStack() *[]byte {
+	bufp := panicBufPool.Get().(*[]byte)
+	buf := (*bufp)[:cap(*bufp)]
+	if size := int(atomic.LoadInt64(&panicStackSize)); len(buf) < size {
+		buf = make([]byte, size)
+	}
+	for {
+		n := runtime.Stack(buf, false)
+		if n < len(buf) || len(buf) >= maxPanicStackSize {
+			*bufp = buf[:n]
+			return bufp
+		}
+		size := 2 * len(buf)
+		if size > maxPanicStackSize {
+			size = maxPanicStackSize
+		}
+		buf = make([]byte, size)
+	}
+}
+
+// putPanicStack 归还缓冲区, 扩容后的缓冲区一并复用, 后续的panic不会再次截断
+func putPanicStack(bufp *[]byte) {
+	*bufp = (*bufp)[:0]
+	panicBufPool.Put(bufp)
+}
@@ -0,0 +1,65 @@
+package sunerror
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// payloadCapture 请求/响应负载的引用, 只在需要完整内容的场景(MarshalJSON、快照、崩溃报告)首次使用时序列化
+type payloadCapture struct {
+	once      sync.Once
+	req, resp interface{}
+	reqText   string
+	respText  string
+}
+
+// WithPayloadCaptureOption 保存请求与响应负载的引用, 用于排查与下游集成的问题而不需要复现
+// 构造时不序列化, 只在MarshalJSON、SnapshotSink、崩溃报告等完整输出中按JSON序列化(无法序列化时按%+v格式化),
+// Error()与日志、对外响应中不包含; 受Limits.MaxPayload限制, 标记PIIPayload时脱敏, External()时去除
+// req/resp在错误的生命周期内不应再被修改, 传nil表示没有对应的负载
+func WithPayloadCaptureOption(req, resp interface{}) SunErrOption {
+	return func(e *SunError) {
+		e.payload = &payloadCapture{req: req, resp: resp}
+	}
+}
+
+// GetPayload 序列化后的请求与响应负载, 未设置WithPayloadCaptureOption时都为空
+func (e *SunError) GetPayload() (req, resp string) {
+	if e.payload == nil {
+		return "", ""
+	}
+	e.payload.once.Do(func() {
+		max := loadConfig().Limits.MaxPayload
+		e.payload.reqText = truncate(payloadText(e.payload.req), max)
+		e.payload.respText = truncate(payloadText(e.payload.resp), max)
+		e.payload.req, e.payload.resp = nil, nil
+	})
+	return e.mask(PIIPayload, e.payload.reqText), e.mask(PIIPayload, e.payload.respText)
+}
+
+func payloadText(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%+v", v)
+	}
+	return string(data)
+}
+
+// restoredPayload 从序列化结果还原的负载, 已经是文本
+func restoredPayload(req, resp string) *payloadCapture {
+	if len(req) == 0 && len(resp) == 0 {
+		return nil
+	}
+	p := &payloadCapture{reqText: req, respText: resp}
+	p.once.Do(func() {})
+	return p
+}
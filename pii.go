@@ -0,0 +1,120 @@
+package sunerror
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// PIIField 可以标记为个人敏感信息(PII)的字段, 可按位组合
+type PIIField uint8
+
+const (
+	// PIIDetail detail中包含PII, 如手机号/证件号
+	PIIDetail PIIField = 1 << iota
+	// PIIChannelMsg 下游返回的错误信息中包含PII
+	PIIChannelMsg
+	// PIIPayload WithPayloadCaptureOption保存的请求/响应中包含PII
+	PIIPayload
+)
+
+// MaskStrategy PII字段的脱敏方式
+type MaskStrategy int8
+
+const (
+	// MaskPartial 保留首尾各2个字符, 中间替换为*, 默认方式
+	MaskPartial MaskStrategy = iota
+	// MaskHash 替换为SHA-256的前16位十六进制, 相同内容的脱敏结果相同, 可用于关联排查
+	MaskHash
+	// MaskDrop 替换为空字符串
+	MaskDrop
+)
+
+// MaskAudit 审计回调, 构造带有PII字段的错误时调用, 记录哪些字段以何种方式脱敏
+type MaskAudit func(ctx context.Context, e *SunError, fields PIIField, strategy MaskStrategy)
+
+var (
+	maskMu          sync.RWMutex
+	defaultStrategy = MaskPartial
+	maskAudit       MaskAudit
+)
+
+// SetMaskStrategy 设置未通过WithPIIOption指定方式时的全局脱敏方式
+func SetMaskStrategy(strategy MaskStrategy) {
+	maskMu.Lock()
+	defer maskMu.Unlock()
+	defaultStrategy = strategy
+}
+
+// SetMaskAudit 设置脱敏审计回调, 在NewSunError返回前同步调用, 应尽快返回; 传nil取消审计
+func SetMaskAudit(audit MaskAudit) {
+	maskMu.Lock()
+	defer maskMu.Unlock()
+	maskAudit = audit
+}
+
+// WithPIIOption 将fields标记为PII, GetDetail/GetChannelMsg及Error()、日志、所有序列化与对外渲染都只能拿到脱敏后的内容
+// strategy不传时使用SetMaskStrategy设置的全局方式
+//
+//	sunerror.NewSunError(ctx, "USER_1001", "FAILED", "bind phone failed",
+//		sunerror.WithDetailOption("phone=%s", phone), sunerror.WithPIIOption(sunerror.PIIDetail))
+func WithPIIOption(fields PIIField, strategy ...MaskStrategy) SunErrOption {
+	return func(e *SunError) {
+		e.pii |= fields
+		if len(strategy) > 0 {
+			e.maskBy = strategy[0]
+			e.hasMaskBy = true
+		}
+	}
+}
+
+// PIIFields 被标记为PII的字段
+func (e *SunError) PIIFields() PIIField {
+	return e.pii
+}
+
+// mask 按错误的脱敏方式处理field的内容, field未标记为PII时原样返回
+func (e *SunError) mask(field PIIField, s string) string {
+	if e.pii&field == 0 || len(s) == 0 {
+		return s
+	}
+	return maskString(s, e.maskStrategy())
+}
+
+func (e *SunError) maskStrategy() MaskStrategy {
+	if e.hasMaskBy {
+		return e.maskBy
+	}
+	maskMu.RLock()
+	defer maskMu.RUnlock()
+	return defaultStrategy
+}
+
+// auditMask 构造完成后调用审计回调
+func (e *SunError) auditMask(ctx context.Context) {
+	maskMu.RLock()
+	audit := maskAudit
+	maskMu.RUnlock()
+	if audit != nil {
+		audit(ctx, e, e.pii, e.maskStrategy())
+	}
+}
+
+func maskString(s string, strategy MaskStrategy) string {
+	switch strategy {
+	case MaskDrop:
+		return ""
+	case MaskHash:
+		sum := sha256.Sum256([]byte(s))
+		return "sha256:" + hex.EncodeToString(sum[:8])
+	}
+	n := utf8.RuneCountInString(s)
+	if n <= 4 {
+		return strings.Repeat("*", n)
+	}
+	runes := []rune(s)
+	return string(runes[:2]) + strings.Repeat("*", n-4) + string(runes[n-2:])
+}
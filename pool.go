@@ -0,0 +1,53 @@
+package sunerror
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// pooledSunError 对象池中的SunError及其堆栈缓冲区
+type pooledSunError struct {
+	SunError
+	stackBuf bytes.Buffer
+	cache    errorCache
+}
+
+var (
+	sunErrPool = sync.Pool{
+		New: func() interface{} {
+			return new(pooledSunError)
+		},
+	}
+	panicBufPool = sync.Pool{
+		New: func() interface{} {
+			buf := make([]byte, atomic.LoadInt64(&panicStackSize))
+			return &buf
+		},
+	}
+)
+
+// AcquireSunError 从对象池获取SunError, 行为与NewSunError一致, 用于错误频繁产生的热点路径
+// 使用完后调用Release归还; 归还后不能再访问该错误, 也不能把它返回给调用方或保存起来
+func AcquireSunError(ctx context.Context, code Code, status, msg string, opts ...SunErrOption) *SunError {
+	p := sunErrPool.Get().(*pooledSunError)
+	p.stackBuf.Reset()
+	p.cache = errorCache{}
+	p.SunError.pooled = p
+	p.SunError.errCache = &p.cache
+	p.SunError.init(ctx, string(code), status, msg, &p.stackBuf, opts)
+	return &p.SunError
+}
+
+// Release 将AcquireSunError获取的错误归还对象池, 其他来源的错误会被忽略
+// 已提交异步执行器的错误可能仍在被执行器使用, 不会归还而是交给GC回收
+// 归还前通过AppendDetail、Translator等派生出的副本不受影响, 可以继续使用
+func Release(e *SunError) {
+	if e == nil || e.pooled == nil || e.async {
+		return
+	}
+	p := e.pooled
+	p.SunError = SunError{}
+	sunErrPool.Put(p)
+}
@@ -0,0 +1,106 @@
+package sunerror
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ProblemContentType RFC 7807 problem+json的Content-Type
+const ProblemContentType = "application/problem+json"
+
+// ProblemDetails RFC 7807 problem+json文档, code/bizStatus/channel信息作为扩展成员
+type ProblemDetails struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status,omitempty"` // HTTP状态码, 由WriteProblem写入
+	Detail        string         `json:"detail,omitempty"`
+	Instance      string         `json:"instance,omitempty"`
+	Code          string         `json:"code"`
+	BizStatus     string         `json:"bizStatus,omitempty"`
+	ChannelCode   string         `json:"channelCode,omitempty"`
+	ChannelMsg    string         `json:"channelMsg,omitempty"`
+	UserMsg       string         `json:"userMsg,omitempty"`
+	DocsURL       string         `json:"docsURL,omitempty"`
+	Kind          string         `json:"kind,omitempty"`
+	Retryable     bool           `json:"retryable,omitempty"`
+	InvalidParams []InvalidParam `json:"invalid-params,omitempty"`
+}
+
+// InvalidParam RFC 7807示例中的invalid-params成员, 对应一个FieldViolation
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+	Rule   string `json:"rule,omitempty"`
+}
+
+// ToProblemDetails 转换为RFC 7807文档, type为baseURL拼接错误码, title为msg, instance为errorID
+// 合规模式下不包含detail与下游信息, 见External
+func (e *SunError) ToProblemDetails(baseURL string) ProblemDetails {
+	e = e.External()
+	var invalidParams []InvalidParam
+	for _, v := range e.violations {
+		invalidParams = append(invalidParams, InvalidParam{Name: v.Field, Reason: v.Message, Rule: v.Rule})
+	}
+	return ProblemDetails{
+		Type:          problemType(baseURL, e.code),
+		Title:         e.msg,
+		Detail:        e.GetDetail(),
+		Instance:      e.GetErrorID(),
+		Code:          e.code,
+		BizStatus:     e.status,
+		ChannelCode:   e.channelCode,
+		ChannelMsg:    e.GetChannelMsg(),
+		UserMsg:       e.userMsg,
+		DocsURL:       e.docsURL,
+		Kind:          kindName(e.kind),
+		Retryable:     e.retryable,
+		InvalidParams: invalidParams,
+	}
+}
+
+// FromProblemDetails 从下游返回的problem+json文档还原SunError, 还原时不打印日志也不保存本地堆栈
+// 文档中不包含fnName与日志等级, 还原后fnName为opts设置的值或调用点, 等级为ErrorLevel或opts设置的值
+func FromProblemDetails(ctx context.Context, p ProblemDetails, opts ...SunErrOption) *SunError {
+	kind, _ := ParseKind(p.Kind)
+	fields := []SunErrOption{
+		WithNoLogOption(),
+		WithStackOption(false),
+		WithRestoredOption(),
+		WithSkipDepthOption(1),
+		WithDetailOption("%s", p.Detail),
+		WithChannelRespOption(p.ChannelCode, p.ChannelMsg),
+		WithErrorIDOption(p.Instance),
+		WithUserMsgOption(p.UserMsg),
+		WithDocsURLOption(p.DocsURL),
+		WithKindOption(kind),
+		WithRetryableOption(p.Retryable),
+	}
+	if len(p.InvalidParams) > 0 {
+		violations := make([]FieldViolation, len(p.InvalidParams))
+		for i, param := range p.InvalidParams {
+			violations[i] = FieldViolation{Field: param.Name, Rule: param.Rule, Message: param.Reason}
+		}
+		fields = append(fields, WithViolationsOption(violations...), WithDetailOption("%s", p.Detail))
+	}
+	return NewSunError(ctx, Code(p.Code), p.BizStatus, p.Title, append(fields, opts...)...)
+}
+
+// WriteProblem 以application/problem+json格式将错误写入http.ResponseWriter, 设置了建议重试间隔时同时写入Retry-After
+func WriteProblem(w http.ResponseWriter, httpStatus int, baseURL string, e *SunError) error {
+	problem := e.ToProblemDetails(baseURL)
+	problem.Status = httpStatus
+	w.Header().Set("Content-Type", ProblemContentType)
+	setRetryAfter(w.Header(), e)
+	w.WriteHeader(httpStatus)
+	return json.NewEncoder(w).Encode(problem)
+}
+
+func problemType(baseURL, code string) string {
+	if len(baseURL) == 0 {
+		return url.PathEscape(code)
+	}
+	return strings.TrimRight(baseURL, "/") + "/" + url.PathEscape(code)
+}
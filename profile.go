@@ -0,0 +1,95 @@
+package sunerror
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+// Profile 按运行环境预设的一组全局配置
+type Profile int8
+
+const (
+	// DevProfile 本地开发: 带颜色与源码片段的ConsoleEngine, 保存32行完整堆栈, 不采样, 对外响应保留内部信息
+	DevProfile Profile = iota
+	// TestProfile 测试: StableStack便于与golden file对比, 不采样, 不开启合规模式
+	TestProfile
+	// ProdProfile 生产: 开启合规模式, 每个指纹每分钟最多打印100条日志, 异步执行器(上报/打点)不采样, 保存10行堆栈
+	ProdProfile
+)
+
+// ProfileEnv 通过环境变量选择预设, 取值为dev/test/prod
+const ProfileEnv = "SUNERROR_PROFILE"
+
+var profileNames = [...]string{
+	DevProfile:  "dev",
+	TestProfile: "test",
+	ProdProfile: "prod",
+}
+
+func (p Profile) String() string {
+	if p >= 0 && int(p) < len(profileNames) {
+		return profileNames[p]
+	}
+	return "unknown"
+}
+
+// ParseProfile 按String()的结果解析预设, 不区分大小写, 也接受development/production
+func ParseProfile(name string) (Profile, bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "dev", "development":
+		return DevProfile, true
+	case "test":
+		return TestProfile, true
+	case "prod", "production":
+		return ProdProfile, true
+	}
+	return DevProfile, false
+}
+
+// UseProfile 应用预设, 修改全局配置(Configure)、StackMode、严格模式、合规模式与日志、异步执行采样, 应在启动时调用
+// 已通过Configure设置的日志引擎保持不变, DevProfile只在没有日志引擎时使用ConsoleEngine
+func UseProfile(p Profile) {
+	cfg := DefaultConfig()
+	switch p {
+	case DevProfile:
+		cfg.DefaultStackRows = 32
+		cfg.StoreStackDefault = true
+		cfg.EnableDebug = true
+		if cfg.LogEngine == nil {
+			cfg.LogEngine = NewConsoleEngine(os.Stderr, WithConsoleColor(true), WithSourceSnippet(2)).Log
+		}
+		SetStackMode(FullStack)
+		SetStrictMode(false)
+		SetComplianceMode(false)
+		SetLogSampling(SamplingConfig{})
+		SetAsyncSampling(SamplingConfig{})
+	case TestProfile:
+		cfg.DefaultStackRows = 10
+		cfg.StoreStackDefault = true
+		SetStackMode(StableStack)
+		SetStrictMode(true)
+		SetComplianceMode(false)
+		SetLogSampling(SamplingConfig{})
+		SetAsyncSampling(SamplingConfig{})
+	case ProdProfile:
+		cfg.DefaultStackRows = 10
+		cfg.StoreStackDefault = true
+		SetStackMode(FullStack)
+		SetStrictMode(false)
+		SetComplianceMode(true)
+		SetLogSampling(SamplingConfig{FirstN: 100, Window: time.Minute})
+		SetAsyncSampling(SamplingConfig{})
+	}
+	Configure(cfg)
+}
+
+// UseProfileFromEnv 按环境变量SUNERROR_PROFILE应用预设, 未设置或无法识别时使用fallback, 返回实际应用的预设
+func UseProfileFromEnv(fallback Profile) Profile {
+	p, ok := ParseProfile(os.Getenv(ProfileEnv))
+	if !ok {
+		p = fallback
+	}
+	UseProfile(p)
+	return p
+}
@@ -0,0 +1,6 @@
+//go:build race
+
+package sunerror
+
+// raceEnabled -race下分配次数会增加, 分配预算的测试跳过
+const raceEnabled = true
@@ -0,0 +1,107 @@
+package sunerror
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"unicode/utf8"
+)
+
+// WebSocket关闭码(RFC 6455)
+const (
+	CloseNormal          = 1000
+	ClosePolicyViolation = 1008
+	CloseInternalError   = 1011
+	CloseTryAgainLater   = 1013
+	// CloseUnauthorized 4000-4999为应用自定义区间, 用于未认证
+	CloseUnauthorized = 4001
+	// CloseForbidden 无权限
+	CloseForbidden = 4003
+	// CloseNotFound 资源不存在
+	CloseNotFound = 4004
+)
+
+// maxCloseReason WebSocket关闭帧的控制帧负载上限为125字节, 去掉2字节关闭码
+const maxCloseReason = 123
+
+var (
+	closeCodeMu sync.RWMutex
+	closeCodes  = map[Code]int{}
+)
+
+// RegisterCloseCode 为指定错误码注册WebSocket关闭码, 优先于按kind的默认映射
+func RegisterCloseCode(code Code, closeCode int) {
+	closeCodeMu.Lock()
+	defer closeCodeMu.Unlock()
+	closeCodes[code] = closeCode
+}
+
+// WebSocketCloseCode 返回SunError对应的WebSocket关闭码
+// 优先使用RegisterCloseCode注册的映射, 否则按kind: 校验失败1008, 未认证4001, 无权限4003, 不存在4004,
+// 可重试/下游/超时/临时故障1013, 其他1011
+func (e *SunError) WebSocketCloseCode() int {
+	closeCodeMu.RLock()
+	closeCode, ok := closeCodes[Code(e.code)]
+	closeCodeMu.RUnlock()
+	if ok {
+		return closeCode
+	}
+	switch e.kind {
+	case ValidationKind, ConflictKind:
+		return ClosePolicyViolation
+	case UnauthenticatedKind:
+		return CloseUnauthorized
+	case PermissionDeniedKind:
+		return CloseForbidden
+	case NotFoundKind:
+		return CloseNotFound
+	case DownstreamKind, TimeoutKind, TransientKind:
+		return CloseTryAgainLater
+	}
+	if e.retryable {
+		return CloseTryAgainLater
+	}
+	return CloseInternalError
+}
+
+// CloseReason 返回关闭帧的reason(错误码:msg), 按UTF-8字符边界截断到关闭帧允许的长度
+func (e *SunError) CloseReason() string {
+	reason := e.code + ": " + e.msg
+	if len(reason) <= maxCloseReason {
+		return reason
+	}
+	cut := maxCloseReason
+	for cut > 0 && !utf8.RuneStart(reason[cut]) {
+		cut--
+	}
+	return reason[:cut]
+}
+
+// CloseMessage 返回WebSocket关闭帧负载(2字节关闭码 + reason), 可用于gorilla/websocket的WriteControl(CloseMessage, ...)
+func (e *SunError) CloseMessage() []byte {
+	reason := e.CloseReason()
+	buf := make([]byte, 2+len(reason))
+	binary.BigEndian.PutUint16(buf, uint16(e.WebSocketCloseCode()))
+	copy(buf[2:], reason)
+	return buf
+}
+
+// WriteSSE 以SSE的"event: error"格式写入错误, data为sunerror.ResponseBody的JSON
+// 不写id字段, 避免覆盖客户端用于断线续传的Last-Event-ID
+// w实现了http.Flusher时会立即flush
+func WriteSSE(w io.Writer, e *SunError) error {
+	data, err := json.Marshal(e.ToResponseBody())
+	if err != nil {
+		return err
+	}
+	if _, err = fmt.Fprintf(w, "event: error\ndata: %s\n\n", data); err != nil {
+		return err
+	}
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
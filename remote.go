@@ -0,0 +1,67 @@
+package sunerror
+
+import "encoding/json"
+
+// remoteSection 从远端服务传来的错误在远端的调用点与堆栈, 与本地的fnName/堆栈分开保存
+type remoteSection struct {
+	fnName string
+	stack  string
+}
+
+// WithRemoteOption 标记错误是从远端服务传来的错误还原的, 记录远端的调用点与堆栈文本, 两者都为空时不设置
+// 本地的fnName与堆栈仍为还原错误的调用点; Error()在本地堆栈之后以"--- remote [fnName] ---"为边界单独输出远端堆栈,
+// 再用Wrap在本地包装一层时, 原始错误中也保留该边界, 可以分清哪些栈帧属于哪个服务
+func WithRemoteOption(fnName, stack string) SunErrOption {
+	return func(e *SunError) {
+		if len(fnName) == 0 && len(stack) == 0 {
+			return
+		}
+		e.remote = &remoteSection{fnName: fnName, stack: stack}
+	}
+}
+
+// GetRemote 远端的调用点与堆栈, ok为false表示不是从远端错误还原的
+func (e *SunError) GetRemote() (fnName, stack string, ok bool) {
+	if e.remote == nil {
+		return "", "", false
+	}
+	return e.remote.fnName, e.remote.stack, true
+}
+
+// remoteText Error()中远端的部分: 边界标记行与远端堆栈, 未设置时为空
+func (e *SunError) remoteText() string {
+	r := e.remote
+	if r == nil {
+		return ""
+	}
+	s := "--- remote [" + r.fnName + "] ---\n" + r.stack
+	if len(r.stack) > 0 && r.stack[len(r.stack)-1] != '\n' {
+		s += "\n"
+	}
+	return s
+}
+
+// equalRemote 与本地一样只比较调用点, 不比较堆栈
+func equalRemote(a, b *remoteSection) bool {
+	var af, bf string
+	if a != nil {
+		af = a.fnName
+	}
+	if b != nil {
+		bf = b.fnName
+	}
+	return af == bf
+}
+
+// parseRemoteBody 从MarshalJSON格式的下游响应体中取出远端的fnName与堆栈, 不是该格式时为空
+func parseRemoteBody(body []byte) (fnName, stack string) {
+	var r struct {
+		Code   string `json:"code"`
+		FnName string `json:"fnName"`
+		Stack  string `json:"stack"`
+	}
+	if len(body) == 0 || json.Unmarshal(body, &r) != nil || len(r.Code) == 0 {
+		return "", ""
+	}
+	return r.FnName, r.Stack
+}
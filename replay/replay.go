@@ -0,0 +1,128 @@
+// Package replay 读取持久化的错误记录(JSON/protobuf等)并还原为SunError, 重新执行全局钩子或指定的函数
+// 用于上报管道故障恢复后补发错误(如重新发送到新的Sentry项目), 或按更新后的装饰器(映射表)重新分类历史错误
+//
+//	f, _ := os.Open("errors.jsonl")
+//	res, err := replay.Lines(ctx, f, sunerror.FormatJSON, replay.Options{Redecorate: true})
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+
+	"github.com/sjmshsh/sunerror"
+	"github.com/sjmshsh/sunerror/sunerrorpb"
+	"google.golang.org/protobuf/encoding/protodelim"
+)
+
+// Options 重放的配置
+type Options struct {
+	Redecorate bool                                              // 执行前按当前注册的装饰器重新装饰, 见SunError.Redecorate
+	Filter     func(e *sunerror.SunError) bool                   // 只重放返回true的错误, 在重新装饰之后判断, 为nil时全部重放
+	Hooks      []func(ctx context.Context, e *sunerror.SunError) // 不为空时只执行这些函数而不执行全局钩子
+}
+
+// Result 重放的统计
+type Result struct {
+	Read     int // 读取的记录数
+	Invalid  int // 无法解析而跳过的记录数
+	Filtered int // 被Filter过滤的错误数
+	Replayed int // 重放的错误数
+}
+
+// Errors 依次重放已经还原的错误, 如sunerror.ReadSnapshots的结果; ctx取消时停止并返回ctx的错误
+func Errors(ctx context.Context, errs []*sunerror.SunError, opts Options) (Result, error) {
+	var res Result
+	for _, e := range errs {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		res.Read++
+		replay(ctx, e, opts, &res)
+	}
+	return res, nil
+}
+
+// Snapshots 重放SnapshotSink写入目录的全部快照
+func Snapshots(ctx context.Context, dir string, opts Options) (Result, error) {
+	errs, err := sunerror.ReadSnapshots(dir)
+	res, replayErr := Errors(ctx, errs, opts)
+	if err != nil {
+		return res, err
+	}
+	return res, replayErr
+}
+
+// Lines 重放每行一条记录的流, 每行按format(sunerror.FormatJSON、FormatText或RegisterSerializer注册的格式)还原
+// 空行忽略, 无法还原的行计入Invalid后跳过; ctx取消或读取失败时停止
+func Lines(ctx context.Context, r io.Reader, format string, opts Options) (Result, error) {
+	if _, ok := sunerror.GetSerializer(format); !ok {
+		return Result{}, errors.New("replay: unknown serializer " + format)
+	}
+	var res Result
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		res.Read++
+		e, err := sunerror.Decode(format, line)
+		if err != nil || e == nil {
+			res.Invalid++
+			continue
+		}
+		replay(ctx, e, opts, &res)
+	}
+	return res, scanner.Err()
+}
+
+// Proto 重放以长度前缀(varint)分隔的sunerrorpb.Error流, 即protodelim.MarshalTo逐条写入的格式
+// 消息无法解析时无法定位下一条记录, 返回错误
+func Proto(ctx context.Context, r io.Reader, opts Options) (Result, error) {
+	var res Result
+	br := bufio.NewReader(r)
+	for {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		m := new(sunerrorpb.Error)
+		if err := protodelim.UnmarshalFrom(br, m); err != nil {
+			if errors.Is(err, io.EOF) {
+				return res, nil
+			}
+			return res, err
+		}
+		res.Read++
+		e, err := sunerrorpb.FromProto(m)
+		if err != nil {
+			res.Invalid++
+			continue
+		}
+		replay(ctx, e, opts, &res)
+	}
+}
+
+func replay(ctx context.Context, e *sunerror.SunError, opts Options, res *Result) {
+	if opts.Redecorate {
+		e = e.Redecorate(ctx)
+	}
+	if opts.Filter != nil && !opts.Filter(e) {
+		res.Filtered++
+		return
+	}
+	res.Replayed++
+	if len(opts.Hooks) == 0 {
+		sunerror.RunGlobalHooks(ctx, e)
+		return
+	}
+	for _, hook := range opts.Hooks {
+		hook(ctx, e)
+	}
+}
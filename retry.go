@@ -0,0 +1,135 @@
+package sunerror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// RetryPolicy Retry的重试策略, 零值字段使用默认值
+type RetryPolicy struct {
+	MaxAttempts    int              // 最大尝试次数(含首次), 默认3
+	InitialBackoff time.Duration    // 首次重试前的等待时间, 默认100ms
+	MaxBackoff     time.Duration    // 等待时间上限, 默认5s
+	Multiplier     float64          // 每次重试等待时间的放大倍数, 默认2
+	Jitter         float64          // 等待时间的随机抖动比例(0~1), 默认0.2, 小于0时不抖动
+	Retryable      func(error) bool // 判断是否重试, 默认为IsRetryable
+}
+
+// retryAfterer 携带下游建议重试间隔(Retry-After/RetryInfo)的错误
+type retryAfterer interface {
+	GetRetryAfter() (time.Duration, bool)
+}
+
+// IsRetryable err链中存在可重试且没有副作用(见WithSideEffectOption)的SunError时返回true
+func IsRetryable(err error) bool {
+	var sunErr *SunError
+	return errors.As(err, &sunErr) && sunErr.IsRetryable() && !sunErr.HasSideEffect()
+}
+
+// Retry 执行fn, 返回可重试的错误时按指数退避重试; 错误携带建议重试间隔时优先使用该间隔, 但不超过MaxBackoff
+// 最终失败的SunError会在detail中追加每次尝试的结果, ctx结束时立即返回最后一次的错误
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	policy = policy.withDefaults()
+	backoff := policy.InitialBackoff
+	var history []string
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		history = append(history, fmt.Sprintf("#%d %s", attempt, attemptSummary(err)))
+		if attempt >= policy.MaxAttempts || !policy.Retryable(err) {
+			return withAttemptHistory(err, attempt, history)
+		}
+
+		wait := policy.jitter(backoff)
+		var ra retryAfterer
+		if errors.As(err, &ra) {
+			if d, ok := ra.GetRetryAfter(); ok {
+				wait = min(d, policy.MaxBackoff)
+			}
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			history = append(history, "ctx done: "+ctx.Err().Error())
+			return withAttemptHistory(err, attempt, history)
+		case <-timer.C:
+		}
+		backoff = time.Duration(float64(backoff) * policy.Multiplier)
+		if backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+	}
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 3
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = 100 * time.Millisecond
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = 5 * time.Second
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = 2
+	}
+	switch {
+	case p.Jitter < 0:
+		p.Jitter = 0
+	case p.Jitter == 0 || p.Jitter > 1:
+		p.Jitter = 0.2
+	}
+	if p.Retryable == nil {
+		p.Retryable = IsRetryable
+	}
+	return p
+}
+
+func (p RetryPolicy) jitter(d time.Duration) time.Duration {
+	delta := float64(d) * p.Jitter
+	return time.Duration(float64(d) - delta + rand.Float64()*2*delta)
+}
+
+func attemptSummary(err error) string {
+	var sunErr *SunError
+	if errors.As(err, &sunErr) {
+		return "code=" + sunErr.code + " channelCode=" + sunErr.GetChannelCode()
+	}
+	return err.Error()
+}
+
+// withAttemptHistory 在SunError的detail中追加每次尝试的结果, SunError被其他错误包装时保留外层的包装
+func withAttemptHistory(err error, attempts int, history []string) error {
+	summary := fmt.Sprintf("attempts=%d [%s]", attempts, strings.Join(history, ", "))
+	var sunErr *SunError
+	if !errors.As(err, &sunErr) {
+		return fmt.Errorf("%s: %w", summary, err)
+	}
+	annotated := sunErr.AppendDetail("%s", summary)
+	if err == error(sunErr) {
+		return annotated
+	}
+	return &attemptError{err: err, sunErr: annotated}
+}
+
+// attemptError Error()与errors.Is按原错误, errors.As(*SunError)优先得到追加了尝试记录的SunError
+type attemptError struct {
+	err    error
+	sunErr *SunError
+}
+
+func (e *attemptError) Error() string {
+	return e.err.Error()
+}
+
+func (e *attemptError) Unwrap() []error {
+	return []error{e.sunErr, e.err}
+}
@@ -0,0 +1,66 @@
+package sunerror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryKeepsOuterWrapping(t *testing.T) {
+	ctx := context.Background()
+	inner := NewSunError(ctx, "RETRY_1", "500", "downstream failed", WithRetryableOption(true), WithNoLogOption(), WithStackOption(false))
+	wrapped := fmt.Errorf("call inventory: %w", inner)
+	attempts := 0
+	err := Retry(ctx, RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}, func(ctx context.Context) error {
+		attempts++
+		return wrapped
+	})
+	if attempts != 2 {
+		t.Fatalf("attempts = %d, want 2", attempts)
+	}
+	if !errors.Is(err, wrapped) {
+		t.Fatalf("errors.Is(result, wrapped) = false, err = %v", err)
+	}
+	if err.Error() != wrapped.Error() {
+		t.Fatalf("Error() = %q, want %q", err.Error(), wrapped.Error())
+	}
+	var sunErr *SunError
+	if !errors.As(err, &sunErr) || !strings.Contains(sunErr.GetDetail(), "attempts=2") {
+		t.Fatalf("SunError detail missing attempt history: %v", sunErr)
+	}
+}
+
+func TestRetryDirectSunError(t *testing.T) {
+	ctx := context.Background()
+	inner := NewSunError(ctx, "RETRY_2", "500", "failed", WithNoLogOption(), WithStackOption(false))
+	err := Retry(ctx, RetryPolicy{}, func(ctx context.Context) error { return inner })
+	sunErr, ok := err.(*SunError)
+	if !ok {
+		t.Fatalf("err = %T, want *SunError", err)
+	}
+	if !strings.Contains(sunErr.GetDetail(), "attempts=1") {
+		t.Fatalf("detail = %q", sunErr.GetDetail())
+	}
+}
+
+func TestRetryAfterCappedByMaxBackoff(t *testing.T) {
+	ctx := context.Background()
+	inner := NewSunError(ctx, "RETRY_3", "503", "busy", WithRetryableOption(true), WithRetryAfterOption(time.Hour), WithNoLogOption(), WithStackOption(false))
+	start := time.Now()
+	_ = Retry(ctx, RetryPolicy{MaxAttempts: 2, MaxBackoff: 10 * time.Millisecond}, func(ctx context.Context) error { return inner })
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Retry waited %v, want at most MaxBackoff", elapsed)
+	}
+}
+
+func TestRetryJitter(t *testing.T) {
+	if p := (RetryPolicy{Jitter: -1}).withDefaults(); p.jitter(time.Second) != time.Second {
+		t.Fatalf("negative Jitter should disable jitter")
+	}
+	if p := (RetryPolicy{}).withDefaults(); p.Jitter != 0.2 {
+		t.Fatalf("default Jitter = %v, want 0.2", p.Jitter)
+	}
+}
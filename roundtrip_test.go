@@ -0,0 +1,112 @@
+package sunerror
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// roundTripInput 模糊测试生成的错误字段
+type roundTripInput struct {
+	code, status, msg, detail       string
+	channelCode, channelMsg         string
+	userMsg, docsURL, errorID       string
+	kind                            uint8
+	level                           int8
+	retryable, sideEffect, violated bool
+	retryAfter                      int64
+}
+
+func addRoundTripSeeds(f *testing.F) {
+	f.Add("ORDER_1001", "404", "订单不存在", "orderID=1, user=2", "", "", "", "", "", uint8(0), int8(0), false, false, false, int64(0))
+	f.Add("", "", "", "", "", "", "", "", "", uint8(0), int8(0), false, false, false, int64(0))
+	f.Add("PAY_9", "502", "下游 失败 😀", "line1\nline2\t\"q\"", "E100", "timeout=3s, retry", "请稍后重试", "https://docs/x", "id-1", uint8(6), int8(2), true, true, true, int64(1500))
+}
+
+func (in roundTripInput) valid() bool {
+	for _, s := range []string{in.code, in.status, in.msg, in.detail, in.channelCode, in.channelMsg, in.userMsg, in.docsURL, in.errorID} {
+		// JSON会把非法UTF-8替换为U+FFFD, 无法原样还原
+		if !utf8.ValidString(s) {
+			return false
+		}
+	}
+	return true
+}
+
+// build 构造错误, full为false时不设置problem+json不携带的sideEffect、retryAfter与traceID
+func (in roundTripInput) build(full bool) *SunError {
+	opts := []SunErrOption{
+		WithNoLogOption(),
+		WithStackOption(false),
+		WithDetailOption("%s", in.detail),
+		WithChannelRespOption(in.channelCode, in.channelMsg),
+		WithUserMsgOption(in.userMsg),
+		WithDocsURLOption(in.docsURL),
+		WithKindOption(SunErrKind(in.kind % uint8(InternalKind+1))),
+		WithRetryableOption(in.retryable),
+		WithLogLevelOption(SunErrLevel(int(uint8(in.level))%int(FatalLevel+1) - 1)),
+	}
+	if len(in.errorID) > 0 {
+		opts = append(opts, WithErrorIDOption(in.errorID))
+	}
+	if in.violated {
+		opts = append(opts, WithViolationsOption(FieldViolation{Field: in.code, Rule: "required", Message: in.msg}))
+	}
+	if full {
+		opts = append(opts, WithSideEffectOption(in.sideEffect), WithTraceIDOption(in.errorID))
+		if in.retryAfter > 0 {
+			opts = append(opts, WithRetryAfterOption(time.Duration(in.retryAfter)))
+		}
+	}
+	return NewSunError(context.Background(), Code(in.code), in.status, in.msg, opts...)
+}
+
+func FuzzJSONRoundTrip(f *testing.F) {
+	addRoundTripSeeds(f)
+	f.Fuzz(func(t *testing.T, code, status, msg, detail, channelCode, channelMsg, userMsg, docsURL, errorID string,
+		kind uint8, level int8, retryable, sideEffect, violated bool, retryAfter int64) {
+		in := roundTripInput{code, status, msg, detail, channelCode, channelMsg, userMsg, docsURL, errorID, kind, level, retryable, sideEffect, violated, retryAfter}
+		if !in.valid() {
+			t.Skip()
+		}
+		orig := in.build(true)
+		data, err := json.Marshal(orig)
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		decoded := new(SunError)
+		if err := json.Unmarshal(data, decoded); err != nil {
+			t.Fatalf("Unmarshal %s: %v", data, err)
+		}
+		if !Equal(orig, decoded) {
+			t.Fatalf("JSON round trip lost fields\norig:    %+v\ndecoded: %+v\njson: %s", orig.ToRecord(false), decoded.ToRecord(false), data)
+		}
+	})
+}
+
+func FuzzProblemRoundTrip(f *testing.F) {
+	addRoundTripSeeds(f)
+	f.Fuzz(func(t *testing.T, code, status, msg, detail, channelCode, channelMsg, userMsg, docsURL, errorID string,
+		kind uint8, level int8, retryable, sideEffect, violated bool, retryAfter int64) {
+		in := roundTripInput{code, status, msg, detail, channelCode, channelMsg, userMsg, docsURL, errorID, kind, level, retryable, sideEffect, violated, retryAfter}
+		if !in.valid() {
+			t.Skip()
+		}
+		orig := in.build(false)
+		data, err := json.Marshal(orig.ToProblemDetails("https://errors.example.com/"))
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		var problem ProblemDetails
+		if err := json.Unmarshal(data, &problem); err != nil {
+			t.Fatalf("Unmarshal %s: %v", data, err)
+		}
+		// problem+json不携带fnName与日志等级, 由调用方通过opts指定
+		decoded := FromProblemDetails(context.Background(), problem, WithFuncNameOption(orig.GetFnName()), WithLogLevelOption(orig.GetLevel()))
+		if !Equal(orig, decoded) {
+			t.Fatalf("problem+json round trip lost fields\norig:    %+v\ndecoded: %+v\njson: %s", orig.ToRecord(false), decoded.ToRecord(false), data)
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package sunerror
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+type goConfig struct {
+	handler PanicHandler
+	wg      *sync.WaitGroup
+	onError func(ctx context.Context, e *SunError)
+	errArgs [3]string
+	errOpts []SunErrOption
+	pooled  bool
+	level   SunErrLevel
+}
+
+// GoOption SafeGo的配置函数
+type GoOption func(c *goConfig)
+
+// WithGoPanicHandler 使用handler处理本次的panic, 不设置时使用SetPanicHandler设置的全局处理函数
+func WithGoPanicHandler(handler PanicHandler) GoOption {
+	return func(c *goConfig) {
+		c.handler = handler
+	}
+}
+
+// WithGoWaitGroup 启动前调用wg.Add(1), fn返回或panic后调用wg.Done()
+func WithGoWaitGroup(wg *sync.WaitGroup) GoOption {
+	return func(c *goConfig) {
+		c.wg = wg
+	}
+}
+
+// WithGoPanicError 将panic转换为以code/status/msg构造的SunError(detail为panic的值, 堆栈为发生panic的位置)交给onError
+// 设置后不再调用panic处理函数, 错误按opts打印日志与执行执行器
+func WithGoPanicError(code Code, status, msg string, onError func(ctx context.Context, e *SunError), opts ...SunErrOption) GoOption {
+	return func(c *goConfig) {
+		c.onError = onError
+		c.errArgs = [3]string{string(code), status, msg}
+		c.errOpts = opts
+	}
+}
+
+// WithGoAsyncPool 提交到异步执行器共用的有界执行池执行, 而不是启动新的协程
+// level决定使用的优先级队列, 队列已满时按执行池的OverflowPolicy处理, 被丢弃时fn不会执行
+// fn收到的ctx带有工作协程的标记, 以该ctx再提交到执行池时不会在BlockPolicy下阻塞
+func WithGoAsyncPool(level SunErrLevel) GoOption {
+	return func(c *goConfig) {
+		c.pooled = true
+		c.level = level
+	}
+}
+
+// SafeGo 在新协程中执行fn并recover其中的panic, 避免一个协程的panic导致整个进程退出
+// panic时抓取堆栈的缓冲区来自对象池; 默认交给SetPanicHandler设置的处理函数, 未设置时通过Config.LogEngine或标准库log打印
+func SafeGo(ctx context.Context, fn func(ctx context.Context), opts ...GoOption) {
+	var c goConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.wg != nil {
+		c.wg.Add(1)
+	}
+	runCtx := ctx
+	if c.pooled {
+		runCtx = withPoolWorker(ctx)
+	}
+	task := func() {
+		if c.wg != nil {
+			defer c.wg.Done()
+		}
+		c.call(runCtx, fn)
+	}
+	if c.pooled {
+		if !submitAsync(ctx, c.level, task) && c.wg != nil {
+			c.wg.Done()
+		}
+		return
+	}
+	go task()
+}
+
+// call 执行fn并处理panic
+func (c *goConfig) call(ctx context.Context, fn func(ctx context.Context)) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		if c.onError != nil {
+			fields := []SunErrOption{WithDetailOption("panic: %v", r), WithStackRows(32)}
+			c.onError(ctx, NewSunErrorAt(ctx, panicPC(), Code(c.errArgs[0]), c.errArgs[1], c.errArgs[2], append(fields, c.errOpts...)...))
+			return
+		}
+		bufp := panicStack()
+		defer putPanicStack(bufp)
+		if c.handler != nil {
+			c.handler(ctx, r, *bufp)
+			return
+		}
+		handlePanic(ctx, r, *bufp, loadConfig().LogEngine)
+	}()
+	fn(ctx)
+}
+
+// panicPC 在deferred函数中调用, 返回发生panic的调用点: runtime.gopanic之后第一个不属于runtime包的栈帧
+func panicPC() uintptr {
+	var pcs [32]uintptr
+	n := runtime.Callers(2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	inPanic := false
+	for {
+		frame, more := frames.Next()
+		if inPanic && !strings.HasPrefix(frame.Function, "runtime.") {
+			return frame.PC + 1
+		}
+		if frame.Function == "runtime.gopanic" {
+			inPanic = true
+		}
+		if !more {
+			return 0
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package sunerror
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// SamplingConfig 采样配置, 用于在错误风暴时控制成本, 见SetAsyncSampling与SetLogSampling
+type SamplingConfig struct {
+	Rate      float64            // 全局采样率(0~1], 0表示不按比例采样
+	CodeRates map[string]float64 // 按错误码的采样率, 优先于Rate, 为0时该错误码不执行
+	FirstN    int                // 每个窗口内同一指纹最多执行的次数, 0表示不限制
+	Window    time.Duration      // FirstN的统计窗口, 默认1分钟
+}
+
+type sampler struct {
+	mu          sync.Mutex
+	cfg         SamplingConfig
+	windowStart time.Time
+	counts      map[string]int
+}
+
+var (
+	samplerMu     sync.RWMutex
+	activeSampler *sampler
+	logSampler    *sampler
+)
+
+// SetAsyncSampling 设置全局异步执行(执行器与全局钩子)采样, 不影响日志与同步执行器, 传零值SamplingConfig关闭采样
+func SetAsyncSampling(cfg SamplingConfig) {
+	s := newSampler(cfg)
+	samplerMu.Lock()
+	defer samplerMu.Unlock()
+	activeSampler = s
+}
+
+// GetAsyncSampling 返回当前的异步执行采样配置, 未开启采样时返回零值
+func GetAsyncSampling() SamplingConfig {
+	samplerMu.RLock()
+	s := activeSampler
+	samplerMu.RUnlock()
+	return s.config()
+}
+
+// SetLogSampling 设置构造错误时打印日志的采样, 不影响执行器, FatalLevel的错误总是打印, 传零值SamplingConfig关闭采样
+func SetLogSampling(cfg SamplingConfig) {
+	s := newSampler(cfg)
+	samplerMu.Lock()
+	defer samplerMu.Unlock()
+	logSampler = s
+}
+
+// GetLogSampling 返回当前的日志采样配置, 未开启采样时返回零值
+func GetLogSampling() SamplingConfig {
+	samplerMu.RLock()
+	s := logSampler
+	samplerMu.RUnlock()
+	return s.config()
+}
+
+// newSampler 按配置创建采样器, 配置不需要采样时返回nil
+func newSampler(cfg SamplingConfig) *sampler {
+	if cfg.Rate <= 0 && len(cfg.CodeRates) == 0 && cfg.FirstN <= 0 {
+		return nil
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = time.Minute
+	}
+	return &sampler{cfg: cfg, counts: make(map[string]int)}
+}
+
+// config 返回采样器配置的副本, nil采样器返回零值
+func (s *sampler) config() SamplingConfig {
+	if s == nil {
+		return SamplingConfig{}
+	}
+	cfg := s.cfg
+	if cfg.CodeRates != nil {
+		codeRates := make(map[string]float64, len(cfg.CodeRates))
+		for code, rate := range cfg.CodeRates {
+			codeRates[code] = rate
+		}
+		cfg.CodeRates = codeRates
+	}
+	return cfg
+}
+
+// Fingerprint 错误指纹, 由错误码与产生错误的位置组成, 同一调用点产生的同一错误指纹相同
+func (e *SunError) Fingerprint() string {
+	return e.code + "@" + e.GetFnName()
+}
+
+// sampleAsync 判断本次是否执行异步执行器, 先按租户策略, 再按全局采样配置
+func (e *SunError) sampleAsync() bool {
+	if p := e.tenantPolicy; p != nil && !p.sample(e.code) {
+		return false
+	}
+	samplerMu.RLock()
+	s := activeSampler
+	samplerMu.RUnlock()
+	if s == nil {
+		return true
+	}
+	return s.sample(e, time.Now())
+}
+
+// sampleLog 判断本次是否打印日志, FatalLevel不采样
+func (e *SunError) sampleLog() bool {
+	if e.level >= FatalLevel {
+		return true
+	}
+	samplerMu.RLock()
+	s := logSampler
+	samplerMu.RUnlock()
+	if s == nil {
+		return true
+	}
+	return s.sample(e, time.Now())
+}
+
+func (s *sampler) sample(e *SunError, now time.Time) bool {
+	rate, ok := s.cfg.CodeRates[e.code]
+	if ok && rate <= 0 {
+		return false
+	}
+	if !ok {
+		rate = s.cfg.Rate
+	}
+	if rate > 0 && rate < 1 && rand.Float64() >= rate {
+		return false
+	}
+	if s.cfg.FirstN <= 0 {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.windowStart) >= s.cfg.Window {
+		s.windowStart = now
+		s.counts = make(map[string]int, len(s.counts))
+	}
+	fp := e.Fingerprint()
+	if s.counts[fp] >= s.cfg.FirstN {
+		return false
+	}
+	s.counts[fp]++
+	return true
+}
@@ -0,0 +1,50 @@
+package sunerror
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLogSamplingLeavesExecutorsUnsampled(t *testing.T) {
+	resetAsyncPool(t)
+	logs := captureConfigLog(t)
+	SetLogSampling(SamplingConfig{FirstN: 2, Window: time.Minute})
+	t.Cleanup(func() { SetLogSampling(SamplingConfig{}) })
+
+	var executed int64
+	exec := WithAsyncExecutor(func(ctx context.Context, e *SunError) {
+		atomic.AddInt64(&executed, 1)
+	})
+	for i := 0; i < 5; i++ {
+		NewSunError(context.Background(), "SAMPLED_LOG", "500", "storm", WithStackOption(false), exec)
+	}
+	NewSunError(context.Background(), "SAMPLED_LOG", "500", "fatal", WithStackOption(false), WithLogLevelOption(FatalLevel))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if got := len(logs()); got != 3 {
+		t.Fatalf("logged %d errors, want 2 sampled and 1 fatal", got)
+	}
+	if got := atomic.LoadInt64(&executed); got != 5 {
+		t.Fatalf("executor ran %d times, want 5", got)
+	}
+}
+
+func TestProdProfileSamplesLogsOnly(t *testing.T) {
+	old := DefaultConfig()
+	t.Cleanup(func() {
+		UseProfile(DevProfile)
+		Configure(old)
+	})
+	UseProfile(ProdProfile)
+	if got := GetLogSampling(); got.FirstN != 100 || got.Window != time.Minute {
+		t.Fatalf("log sampling = %+v", got)
+	}
+	if got := GetAsyncSampling(); got.Rate != 0 || got.FirstN != 0 || len(got.CodeRates) != 0 {
+		t.Fatalf("async sampling = %+v, want disabled", got)
+	}
+}
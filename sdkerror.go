@@ -0,0 +1,104 @@
+package sunerror
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// FromError无法识别错误时使用的错误码与status
+const (
+	ExternalErrorCode   Code = "EXTERNAL_ERROR"
+	ExternalErrorStatus      = "FAILED"
+)
+
+// SDKError 分类器从第三方SDK错误中识别出的信息
+type SDKError struct {
+	Code        Code       // 错误码, 为空时使用ExternalErrorCode
+	Msg         string     // 错误信息, 为空时使用err.Error()
+	Kind        SunErrKind // 错误分类
+	Retryable   bool       // 是否可重试
+	ChannelCode string     // SDK或服务端返回的原始错误码, 如AWS的NoSuchKey, 以WithChannelRespOption记录
+	ChannelMsg  string     // SDK或服务端返回的原始错误信息
+}
+
+// ErrorClassifier 识别特定SDK的错误(如AWS、go-redis、mongo driver), ok为false时交给下一个分类器
+type ErrorClassifier func(err error) (info SDKError, ok bool)
+
+type namedClassifier struct {
+	name     string
+	classify ErrorClassifier
+}
+
+var (
+	sdkMu          sync.RWMutex
+	sdkClassifiers []namedClassifier
+)
+
+// RegisterErrorClassifier 以name注册SDK错误分类器, 后注册的优先; 同名的分类器被替换, 传nil时删除
+// contrib下的适配包(如contrib/awserr、contrib/goredis)通过Register注册
+func RegisterErrorClassifier(name string, c ErrorClassifier) {
+	sdkMu.Lock()
+	defer sdkMu.Unlock()
+	for i, nc := range sdkClassifiers {
+		if nc.name == name {
+			sdkClassifiers = append(sdkClassifiers[:i:i], sdkClassifiers[i+1:]...)
+			break
+		}
+	}
+	if c != nil {
+		sdkClassifiers = append([]namedClassifier{{name, c}}, sdkClassifiers...)
+	}
+}
+
+// ClassifyError 按已注册的SDK错误分类器依次识别err, 都无法识别时ok为false
+func ClassifyError(err error) (SDKError, bool) {
+	sdkMu.RLock()
+	defer sdkMu.RUnlock()
+	for _, nc := range sdkClassifiers {
+		if info, ok := nc.classify(err); ok {
+			return info, true
+		}
+	}
+	return SDKError{}, false
+}
+
+// FromError 将任意错误转换为SunError并包装原始错误, err为nil时返回nil, 已经是SunError的err原样返回
+// 依次尝试:
+// 1. RegisterErrorClassifier注册的SDK错误分类器, 按识别结果设置错误码、分类、是否可重试与下游错误码/信息
+// 2. 数据库错误分类器, 能识别时等同于FromDBError
+// 3. 都无法识别时为ExternalErrorCode, 与Wrap一样按SetCauseClassifier设置的分类器推断分类(如超时、连接重置)
+// opts中的选项优先于识别结果
+func FromError(ctx context.Context, err error, opts ...SunErrOption) *SunError {
+	if err == nil {
+		return nil
+	}
+	var sunErr *SunError
+	if errors.As(err, &sunErr) {
+		return sunErr
+	}
+	if info, ok := ClassifyError(err); ok {
+		code, msg := info.Code, info.Msg
+		if len(code) == 0 {
+			code = ExternalErrorCode
+		}
+		if len(msg) == 0 {
+			msg = err.Error()
+		}
+		fields := []SunErrOption{
+			WithCauseOption(withContextCause(ctx, err)),
+			WithSkipDepthOption(1),
+			WithKindOption(info.Kind),
+			WithRetryableOption(info.Retryable),
+		}
+		if len(info.ChannelCode) > 0 || len(info.ChannelMsg) > 0 {
+			fields = append(fields, WithChannelRespOption(info.ChannelCode, info.ChannelMsg))
+		}
+		return NewSunError(ctx, code, ExternalErrorStatus, msg, append(fields, opts...)...)
+	}
+	if ClassifyDBError(err) != DBOther {
+		return FromDBError(ctx, err, append([]SunErrOption{WithSkipDepthOption(1)}, opts...)...)
+	}
+	fields := append(causeOptions(ctx, err), WithSkipDepthOption(1))
+	return NewSunError(ctx, ExternalErrorCode, ExternalErrorStatus, err.Error(), append(fields, opts...)...)
+}
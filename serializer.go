@@ -0,0 +1,90 @@
+package sunerror
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Serializer 错误的序列化格式, 实现需要可以并发调用
+type Serializer interface {
+	Encode(e *SunError) ([]byte, error)
+	Decode(data []byte) (*SunError, error)
+}
+
+// 内置的序列化格式名
+const (
+	FormatJSON = "json" // MarshalJSON, 包含堆栈
+	FormatText = "text" // MarshalText, 不含堆栈的URL安全base64
+)
+
+var (
+	serializerMu sync.RWMutex
+	serializers  = map[string]Serializer{
+		FormatJSON: jsonSerializer{},
+		FormatText: textSerializer{},
+	}
+)
+
+// RegisterSerializer 按格式名注册序列化格式, 同名时覆盖(包括内置格式), 在初始化时调用
+func RegisterSerializer(format string, s Serializer) {
+	serializerMu.Lock()
+	defer serializerMu.Unlock()
+	serializers[format] = s
+}
+
+// GetSerializer 返回格式名对应的序列化格式, 未注册时ok为false
+func GetSerializer(format string) (Serializer, bool) {
+	serializerMu.RLock()
+	defer serializerMu.RUnlock()
+	s, ok := serializers[format]
+	return s, ok
+}
+
+// Encode 按格式名序列化错误, 格式未注册时返回错误
+func Encode(format string, e *SunError) ([]byte, error) {
+	s, ok := GetSerializer(format)
+	if !ok {
+		return nil, fmt.Errorf("sunerror: unknown serializer %q", format)
+	}
+	return s.Encode(e)
+}
+
+// Decode 按格式名还原错误, 格式未注册时返回错误
+func Decode(format string, data []byte) (*SunError, error) {
+	s, ok := GetSerializer(format)
+	if !ok {
+		return nil, fmt.Errorf("sunerror: unknown serializer %q", format)
+	}
+	return s.Decode(data)
+}
+
+type jsonSerializer struct{}
+
+func (jsonSerializer) Encode(e *SunError) ([]byte, error) {
+	return e.MarshalJSON()
+}
+
+func (jsonSerializer) Decode(data []byte) (*SunError, error) {
+	var r Record
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+	return FromRecord(r)
+}
+
+type textSerializer struct{}
+
+func (textSerializer) Encode(e *SunError) ([]byte, error) {
+	return e.MarshalText()
+}
+
+func (textSerializer) Decode(data []byte) (*SunError, error) {
+	e := newSunError()
+	cache := e.errCache
+	if err := e.UnmarshalText(data); err != nil {
+		return nil, err
+	}
+	e.errCache = cache
+	return e, nil
+}
@@ -0,0 +1,197 @@
+package sunerror
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SnapshotConfig 本地快照的配置, 零值字段使用默认值
+type SnapshotConfig struct {
+	Dir       string // 快照目录, 不存在时创建
+	MaxFiles  int    // 保留的文件数, 默认4
+	MaxBytes  int64  // 单个文件的大小上限, 默认1MB
+	AllLevels bool   // 保存全部等级的错误, 默认只保存ErrorLevel及以上
+}
+
+const (
+	snapshotPrefix = "sunerror-"
+	snapshotSuffix = ".jsonl"
+)
+
+// SnapshotSink 将错误追加写入本地文件(每行一个MarshalJSON的结果), 日志管道故障时仍可以从主机上找回最近的错误
+// 文件写满MaxBytes后滚动到下一个文件, 只保留最新的MaxFiles个文件, 占用的磁盘空间不超过MaxFiles*MaxBytes
+type SnapshotSink struct {
+	mu   sync.Mutex
+	cfg  SnapshotConfig
+	f    *os.File
+	seq  int
+	size int64
+}
+
+// NewSnapshotSink 创建本地快照, 目录中已有快照时接着最新的文件继续写入
+// 通过WithAsyncExecutor(sink.Save)或AddDefaultOptions注册, 进程退出前调用Close
+func NewSnapshotSink(cfg SnapshotConfig) (*SnapshotSink, error) {
+	if cfg.MaxFiles <= 0 {
+		cfg.MaxFiles = 4
+	}
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = 1 << 20
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	s := &SnapshotSink{cfg: cfg}
+	seqs, err := snapshotSeqs(cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(seqs) > 0 {
+		s.seq = seqs[len(seqs)-1]
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Save 异步执行器函数, 写入失败时丢弃(不能再打印日志, 避免日志故障时递归)
+func (s *SnapshotSink) Save(ctx context.Context, e *SunError) {
+	_ = s.Append(e)
+}
+
+// Append 写入一个错误, 未设置AllLevels时忽略ErrorLevel以下的错误
+func (s *SnapshotSink) Append(e *SunError) error {
+	if !s.cfg.AllLevels && e.level < ErrorLevel {
+		return nil
+	}
+	line, err := e.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return os.ErrClosed
+	}
+	if s.size > 0 && s.size+int64(len(line)) > s.cfg.MaxBytes {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := s.f.Write(line)
+	s.size += int64(n)
+	return err
+}
+
+// Close 关闭当前文件, 之后的Append返回os.ErrClosed
+func (s *SnapshotSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return nil
+	}
+	err := s.f.Close()
+	s.f = nil
+	return err
+}
+
+func (s *SnapshotSink) open() error {
+	f, err := os.OpenFile(snapshotPath(s.cfg.Dir, s.seq), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	s.f, s.size = f, info.Size()
+	return nil
+}
+
+// rotate 滚动到下一个文件并删除超出MaxFiles的旧文件
+func (s *SnapshotSink) rotate() error {
+	if err := s.f.Close(); err != nil {
+		return err
+	}
+	s.seq++
+	if old := s.seq - s.cfg.MaxFiles; old >= 0 {
+		seqs, err := snapshotSeqs(s.cfg.Dir)
+		if err != nil {
+			return err
+		}
+		for _, seq := range seqs {
+			if seq <= old {
+				os.Remove(snapshotPath(s.cfg.Dir, seq))
+			}
+		}
+	}
+	return s.open()
+}
+
+// ReadSnapshots 按写入顺序读取目录中的全部快照, 无法解析的行(如写入一半时进程退出)跳过
+// 读取时不打印日志也不执行执行器
+func ReadSnapshots(dir string) ([]*SunError, error) {
+	seqs, err := snapshotSeqs(dir)
+	if err != nil {
+		return nil, err
+	}
+	var out []*SunError
+	for _, seq := range seqs {
+		f, err := os.Open(snapshotPath(dir, seq))
+		if err != nil {
+			return out, err
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 16<<20)
+		for scanner.Scan() {
+			var r Record
+			if json.Unmarshal(scanner.Bytes(), &r) != nil {
+				continue
+			}
+			if e, err := FromRecord(r); err == nil {
+				out = append(out, e)
+			}
+		}
+		f.Close()
+		if err := scanner.Err(); err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+func snapshotPath(dir string, seq int) string {
+	return filepath.Join(dir, fmt.Sprintf("%s%08d%s", snapshotPrefix, seq, snapshotSuffix))
+}
+
+// snapshotSeqs 目录中快照文件的序号, 升序
+func snapshotSeqs(dir string) ([]int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var seqs []int
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, snapshotPrefix) || !strings.HasSuffix(name, snapshotSuffix) {
+			continue
+		}
+		seq, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, snapshotPrefix), snapshotSuffix))
+		if err == nil {
+			seqs = append(seqs, seq)
+		}
+	}
+	sort.Ints(seqs)
+	return seqs, nil
+}
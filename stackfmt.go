@@ -0,0 +1,144 @@
+package sunerror
+
+import (
+	"encoding/json"
+	"math"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+)
+
+// StackFormatter 调用栈的输出格式, 将栈帧(从调用点开始)追加到dst并返回, 输出应以换行结尾
+// 通过SetStackFormatter全局设置, 或通过Layout.WithStackFormatter、SunError.FormatStack为单个输出目标设置
+type StackFormatter interface {
+	AppendStack(dst []byte, frames []runtime.Frame) []byte
+}
+
+// StackFormatterFunc 以函数实现StackFormatter
+type StackFormatterFunc func(dst []byte, frames []runtime.Frame) []byte
+
+func (f StackFormatterFunc) AppendStack(dst []byte, frames []runtime.Frame) []byte {
+	return f(dst, frames)
+}
+
+var (
+	// TextStack 每帧一行"文件:行号 (PC)", 默认格式
+	TextStack StackFormatter = StackFormatterFunc(appendTextStack)
+	// OneLineStack 所有帧压缩在一行内, 如"pkg.Handle(handler.go:42) <- main.main(main.go:10)", 适合按行采集的日志
+	OneLineStack StackFormatter = StackFormatterFunc(appendOneLineStack)
+	// JSONStack JSON数组, 每帧为{"function","file","line"}, 适合APM等按结构解析的目标
+	JSONStack StackFormatter = StackFormatterFunc(appendJSONStack)
+	// JavaStack 每帧一行"\tat 函数(文件名:行号)", 适合按Java异常格式解析堆栈的日志平台
+	JavaStack StackFormatter = StackFormatterFunc(appendJavaStack)
+)
+
+// stackFormatter SetStackFormatter设置的全局格式, 未设置时为nil
+var stackFormatter atomic.Value
+
+// SetStackFormatter 设置构造错误时保存的调用栈的格式, 影响Error()、MarshalJSON与logfmt中的堆栈, 传nil时恢复TextStack
+// 应在构造错误之前设置, 调用栈在构造时格式化; SetStackMode设置的StableStack/StripStack优先
+func SetStackFormatter(f StackFormatter) {
+	stackFormatter.Store(&f)
+}
+
+func loadStackFormatter() StackFormatter {
+	p, _ := stackFormatter.Load().(*StackFormatter)
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+// FormatStack 按f重新格式化保存的调用栈, 用于同一个错误在不同输出目标中使用不同格式, 如APM用JSONStack而日志用TextStack
+// 未保存堆栈时为空; 从JSON等还原或因截止时间跳过抓取的错误没有栈帧, 以及StableStack/StripStack模式下, 返回保存的文本
+func (e *SunError) FormatStack(f StackFormatter) string {
+	if !e.storeStack {
+		return ""
+	}
+	if len(e.stackPCs) == 0 || f == nil || getStackMode() != FullStack {
+		return string(e.stack)
+	}
+	return string(f.AppendStack(nil, stackFrames(e.stackPCs, e.stackRows)))
+}
+
+// StackFrames 保存的调用栈的栈帧, 未保存堆栈或没有栈帧时为nil
+func (e *SunError) StackFrames() []runtime.Frame {
+	if !e.storeStack || len(e.stackPCs) == 0 {
+		return nil
+	}
+	return stackFrames(e.stackPCs, e.stackRows)
+}
+
+// stackFrames 将PC展开为最多rows个栈帧(内联函数各占一帧), rows<=0时不限制
+func stackFrames(pcs []uintptr, rows int) []runtime.Frame {
+	if rows <= 0 {
+		rows = math.MaxInt
+	}
+	out := make([]runtime.Frame, 0, min(rows, len(pcs)))
+	frames := runtime.CallersFrames(pcs)
+	for len(out) < rows {
+		frame, more := frames.Next()
+		out = append(out, frame)
+		if !more {
+			break
+		}
+	}
+	return out
+}
+
+func appendTextStack(dst []byte, frames []runtime.Frame) []byte {
+	for _, frame := range frames {
+		dst = append(dst, frame.File...)
+		dst = append(dst, ':')
+		dst = strconv.AppendInt(dst, int64(frame.Line), 10)
+		dst = append(dst, " (0x"...)
+		dst = strconv.AppendUint(dst, uint64(frame.PC), 16)
+		dst = append(dst, ")\n"...)
+	}
+	return dst
+}
+
+func appendOneLineStack(dst []byte, frames []runtime.Frame) []byte {
+	for i, frame := range frames {
+		if i > 0 {
+			dst = append(dst, " <- "...)
+		}
+		dst = append(dst, filepath.Base(frame.Function)...)
+		dst = append(dst, '(')
+		dst = append(dst, filepath.Base(frame.File)...)
+		dst = append(dst, ':')
+		dst = strconv.AppendInt(dst, int64(frame.Line), 10)
+		dst = append(dst, ')')
+	}
+	return append(dst, '\n')
+}
+
+type jsonFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+func appendJSONStack(dst []byte, frames []runtime.Frame) []byte {
+	out := make([]jsonFrame, len(frames))
+	for i, frame := range frames {
+		out[i] = jsonFrame{Function: frame.Function, File: frame.File, Line: frame.Line}
+	}
+	data, _ := json.Marshal(out)
+	dst = append(dst, data...)
+	return append(dst, '\n')
+}
+
+func appendJavaStack(dst []byte, frames []runtime.Frame) []byte {
+	for _, frame := range frames {
+		dst = append(dst, "\tat "...)
+		dst = append(dst, frame.Function...)
+		dst = append(dst, '(')
+		dst = append(dst, filepath.Base(frame.File)...)
+		dst = append(dst, ':')
+		dst = strconv.AppendInt(dst, int64(frame.Line), 10)
+		dst = append(dst, ")\n"...)
+	}
+	return dst
+}
@@ -0,0 +1,46 @@
+package sunerror
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// StackMode 调用点(fnName)与调用栈的输出方式
+type StackMode int32
+
+const (
+	// FullStack 输出真实的文件路径、行号与PC, 默认模式
+	FullStack StackMode = iota
+	// StableStack 文件路径只保留文件名, 行号与PC替换为固定的占位符LINE与PC,
+	// 序列化后的错误可以直接与golden file对比, 代码调整行号后不需要更新
+	StableStack
+	// StripStack 不输出调用栈, fnName只保留函数名
+	StripStack
+)
+
+var stackMode int32
+
+// SetStackMode 设置调用点与调用栈的输出方式, 用于测试; 应在构造错误之前设置,
+// 调用栈在构造时格式化, 切换模式不影响已构造的错误
+func SetStackMode(mode StackMode) {
+	atomic.StoreInt32(&stackMode, int32(mode))
+}
+
+func getStackMode() StackMode {
+	return StackMode(atomic.LoadInt32(&stackMode))
+}
+
+// stableFunc 按StableStack/StripStack格式化调用点, 仅测试时使用, 不缓存
+func stableFunc(pc uintptr, mode StackMode) string {
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if len(frame.Function) == 0 {
+		return "??()"
+	}
+	funcName := strings.TrimLeft(filepath.Ext(frame.Function), ".") + "()"
+	if mode == StripStack {
+		return funcName
+	}
+	return filepath.Base(frame.File) + ":LINE:" + funcName
+}
@@ -3,34 +3,85 @@ package sunerror
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
 	"fmt"
 	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 )
 
-const burSize int = 3000
-
-// SunError 自定义Error类型(实现了go内嵌error接口)
+// SunError 自定义Error类型(*SunError实现了go内嵌error接口)
 // 1. 包含三元组(Code + Msg + Status)
 // 2. 自动打印日志, NewSunError时打印
 // 3. 堆栈信息
+// 构造完成后只读, 所有方法均为指针接收者, 避免每次调用复制整个结构体;
+// AppendDetail与Translator等派生操作返回新的副本, 不修改原错误
+// 并发模型: NewSunError返回前完成全部写入, 之后不再修改任何字段, 多个协程(包括异步执行器)
+// 可以同时调用任意方法; 延迟格式化的内容(Error()、detail)由sync.Once保护, 不需要加锁
+// 例外是AcquireSunError获取的错误: Release会清空并复用该结构体, 调用Release前必须确保没有其他协程仍在使用,
+// 之后也不能再访问; 已提交异步执行器的错误Release时不回收, 派生出的副本不受Release影响
 type SunError struct {
-	code        string
-	msg         string
-	status      string
-	level       SunErrLevel
-	detail      string // 单号等打印的补充信息
-	fnName      string
-	storeStack  bool
-	stack       []byte
-	stackRows   int
-	depth       int
-	channelCode string                                        // 下游错误码
-	channelMsg  string                                        // 下游错误信息
-	asyncFn     func(ctx context.Context, sunError *SunError) // 异步执行函数
-	logEngine   logFunc                                       // 用户自定义的日志引擎
+	code          string
+	msg           string
+	status        string
+	level         SunErrLevel
+	detail        string      // 单号等打印的补充信息
+	lazyDetail    *lazyString // WithDetailOption设置的详细信息, 首次使用时才格式化
+	fnName        string
+	pc            uintptr                    // 产生错误的调用点, 未设置fnName时按需格式化为fnName
+	fnFormat      FuncFormat                 // 调用点格式化为fnName的格式
+	fnFormatter   func(runtime.Frame) string // 自定义的fnName格式化函数, 优先于fnFormat
+	storeStack    bool
+	stack         []byte
+	stackPCs      []uintptr // 保存堆栈时的PC, 供FormatStack按其他格式输出
+	stackRows     int
+	depth         int
+	channelCode   string           // 下游错误码
+	channelMsg    string           // 下游错误信息
+	channelCall   *channelCall     // 下游调用的端点、耗时与尝试次数
+	remote        *remoteSection   // 从远端服务还原的错误在远端的调用点与堆栈
+	cause         error            // 被包装的原始错误
+	pii           PIIField         // 标记为PII的字段
+	maskBy        MaskStrategy     // PII字段的脱敏方式
+	hasMaskBy     bool             // 是否单独设置了脱敏方式
+	asyncFns      []asyncExecutor  // 异步执行函数, 按注册顺序执行
+	asyncPar      bool             // 异步执行函数是否并行执行
+	asyncCtx      bool             // 异步执行函数是否使用原始ctx
+	syncFns       []syncExecutor   // 同步执行函数, NewSunError返回前执行
+	syncErr       error            // 同步执行函数返回的错误
+	asyncTTL      time.Duration    // 单次异步执行的超时时间
+	asyncTries    int              // 可重试异步执行器的最大尝试次数
+	asyncDelay    time.Duration    // 可重试异步执行器首次重试前的等待时间
+	logEngine     logFunc          // 用户自定义的日志引擎
+	noLog         bool             // 构造时不打印日志
+	restored      bool             // 从外部数据还原, 错误码由对方决定, 严格模式不校验
+	errorID       string           // 错误唯一ID, 用于关联响应与日志
+	rawID         [8]byte          // 自动生成的errorID, 按需格式化为十六进制
+	hasRawID      bool             // 是否使用自动生成的errorID
+	traceID       string           // 链路追踪ID, 用于跨服务关联同一个错误
+	groupID       string           // 扇出调用的分组ID
+	tenant        string           // 错误所属的租户
+	tenantPolicy  *TenantPolicy    // 构造时确定的租户策略, 只读
+	retryable     bool             // 调用方是否可以重试
+	retryAfter    time.Duration    // 下游建议的重试间隔(Retry-After/RetryInfo)
+	hasRetryAfter bool             // 是否设置了建议重试间隔
+	sideEffect    bool             // 失败的操作可能已经部分执行
+	kind          SunErrKind       // 错误分类
+	userMsg       string           // 面向终端用户的提示
+	violations    []FieldViolation // 字段校验失败明细
+	payload       *payloadCapture  // 请求/响应负载, 按需序列化
+	docsURL       string           // 错误文档链接
+	redact        bool             // 对外渲染时去除内部信息, 由ComplianceTranslator设置
+	async         bool             // 是否已提交异步执行器, 已提交时不能回收到对象池
+	pooled        *pooledSunError  // AcquireSunError获取时指向所在的对象池元素
+	errCache      *errorCache      // Error()结果的缓存, 与SunError在同一次内存分配中
+	def           *Definition      // 产生该错误的定义
 }
 
 // SunErrLevel 错误等级, 会影响日志打印时的level
@@ -40,99 +91,417 @@ type SunErrLevel int8
 type SunErrOption func(sunError *SunError)
 
 const (
+	// DebugLevel Debug级别, 默认不打印日志, 通过Config.EnableDebug开启
+	DebugLevel SunErrLevel = iota - 1
 	// InfoLevel Info级别
-	InfoLevel SunErrLevel = iota
+	InfoLevel
 	// WarnLevel Warn级别
 	WarnLevel
 	// ErrorLevel Error级别
 	ErrorLevel
+	// FatalLevel 不可恢复的错误, 打印日志后执行SetFatalHandler设置的处理(退出、panic或回调)
+	FatalLevel
 )
 
-func (e SunError) Error() string {
-	errInfo := fmt.Sprintf("[%s] code=%s, msg=%s, channelCode=%s, channelMsg=%s, detail=%s",
-		e.fnName, e.code, e.msg, e.channelCode, e.channelMsg, e.detail)
-	if e.storeStack {
-		errInfo = errInfo + "\n" + string(e.stack)
+// Error 首次调用时格式化并缓存结果, 中间件多次调用时不会重复格式化
+func (e *SunError) Error() string {
+	if e.errCache == nil {
+		return e.formatError()
+	}
+	e.errCache.once.Do(func() {
+		e.errCache.s = e.formatError()
+	})
+	return e.errCache.s
+}
+
+// AppendError 将Error()的内容追加到dst并返回, 供日志管道复用缓冲区, 不产生中间字符串
+func (e *SunError) AppendError(dst []byte) []byte {
+	if l := loadErrorLayout(); l != nil {
+		return l.Append(dst, e)
+	}
+	dst = e.appendErrorParts(dst)
+	dst = append(dst, e.channelCallText()...)
+	if e.cause != nil {
+		dst = append(dst, ", cause="...)
+		dst = appendErrorValue(dst, e.causeText())
+	}
+	if e.storeStack && len(e.stack) > 0 {
+		dst = append(dst, '\n')
+		dst = append(dst, e.stack...)
+	}
+	if e.remote != nil {
+		dst = append(dst, '\n')
+		dst = append(dst, e.remoteText()...)
+	}
+	return dst
+}
+
+// formatError 设置了SetErrorLayout时按布局格式化, 否则按各段长度一次分配好strings.Builder的容量后拼接
+func (e *SunError) formatError() string {
+	if l := loadErrorLayout(); l != nil {
+		return l.Format(e)
+	}
+	parts := e.errorParts()
+	n := 0
+	for _, part := range parts {
+		n += len(part)
+	}
+	call := e.channelCallText()
+	n += len(call)
+	var cause string
+	if e.cause != nil {
+		cause = errorValue(e.causeText())
+		n += len(", cause=") + len(cause)
+	}
+	if e.storeStack && len(e.stack) > 0 {
+		n += 1 + len(e.stack)
+	}
+	remote := e.remoteText()
+	if len(remote) > 0 {
+		n += 1 + len(remote)
+	}
+	var b strings.Builder
+	b.Grow(n)
+	for _, part := range parts {
+		b.WriteString(part)
+	}
+	b.WriteString(call)
+	if e.cause != nil {
+		b.WriteString(", cause=")
+		b.WriteString(cause)
+	}
+	if e.storeStack && len(e.stack) > 0 {
+		b.WriteByte('\n')
+		b.Write(e.stack)
+	}
+	if len(remote) > 0 {
+		b.WriteByte('\n')
+		b.WriteString(remote)
+	}
+	return b.String()
+}
+
+// appendErrorParts 同errorParts, 直接追加到dst, 加引号与errorID的十六进制格式化都不产生中间字符串
+func (e *SunError) appendErrorParts(dst []byte) []byte {
+	dst = append(dst, '[')
+	dst = append(dst, e.GetFnName()...)
+	dst = append(dst, "] code="...)
+	dst = appendErrorValue(dst, e.code)
+	dst = append(dst, ", msg="...)
+	dst = appendErrorValue(dst, e.msg)
+	dst = append(dst, ", channelCode="...)
+	dst = appendErrorValue(dst, e.channelCode)
+	dst = append(dst, ", channelMsg="...)
+	dst = appendErrorValue(dst, e.GetChannelMsg())
+	dst = append(dst, ", detail="...)
+	dst = appendErrorValue(dst, e.GetDetail())
+	dst = append(dst, ", errorID="...)
+	if len(e.errorID) == 0 && e.hasRawID {
+		return hex.AppendEncode(dst, e.rawID[:])
+	}
+	return appendErrorValue(dst, e.errorID)
+}
+
+// errorParts Error()除原始错误与堆栈外的各段, 格式为
+// [fnName] code=, msg=, channelCode=, channelMsg=, detail=, errorID=
+// 设置了WithChannelCallInfo时Error()在之后追加", channelEndpoint=, channelLatency=, channelAttempt=", 包装了原始错误时再追加", cause=",
+// 之后另起一行输出本地堆栈, 从远端错误还原时再另起一行输出"--- remote [fnName] ---"与远端堆栈
+// 值中包含分隔符(,与=)、引号、反斜杠或控制字符时按Go语法加引号并转义, 保证按", "与"="切分的日志解析器不会错位
+func (e *SunError) errorParts() [14]string {
+	return [14]string{
+		"[", e.GetFnName(),
+		"] code=", errorValue(e.code),
+		", msg=", errorValue(e.msg),
+		", channelCode=", errorValue(e.channelCode),
+		", channelMsg=", errorValue(e.GetChannelMsg()),
+		", detail=", errorValue(e.GetDetail()),
+		", errorID=", errorValue(e.GetErrorID()),
+	}
+}
+
+// errorValue 按需为Error()中的值加引号, 不需要时原样返回, 不产生分配
+func errorValue(s string) string {
+	if !errorValueNeedsQuote(s) {
+		return s
 	}
-	return errInfo
+	return strconv.Quote(s)
 }
 
-func (e SunError) GetCode() string {
-	return e.code
+func appendErrorValue(dst []byte, s string) []byte {
+	if !errorValueNeedsQuote(s) {
+		return append(dst, s...)
+	}
+	return strconv.AppendQuote(dst, s)
 }
 
-func (e SunError) GetStatus() string {
+// errorValueNeedsQuote 包含,、=、引号、反斜杠、控制字符或非法UTF-8的值需要加引号; 空值与空格不加引号, 与之前的输出保持一致
+func errorValueNeedsQuote(s string) bool {
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c < ' ' || c == 0x7f || c == ',' || c == '=' || c == '"' || c == '\\' {
+				return true
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if (r == utf8.RuneError && size == 1) || !strconv.IsPrint(r) {
+			return true
+		}
+		i += size
+	}
+	return false
+}
+
+func (e *SunError) GetCode() Code {
+	return Code(e.code)
+}
+
+func (e *SunError) GetStatus() string {
 	return e.status
 }
 
-func (e SunError) GetMsg() string {
+func (e *SunError) GetMsg() string {
 	return e.msg
 }
-func (e SunError) GetDetail() string {
+
+// GetLevel 日志打印等级
+func (e *SunError) GetLevel() SunErrLevel {
+	return e.level
+}
+
+// GetDetail 详细信息, 标记为PII时返回脱敏后的内容
+func (e *SunError) GetDetail() string {
+	return e.mask(PIIDetail, e.rawDetail())
+}
+
+func (e *SunError) rawDetail() string {
+	if e.lazyDetail != nil {
+		return e.lazyDetail.String()
+	}
 	return e.detail
 }
 
-func (e SunError) GetChannelCode() string {
+func (e *SunError) GetFnName() string {
+	if len(e.fnName) == 0 && e.pc != 0 {
+		if mode := getStackMode(); mode != FullStack {
+			return stableFunc(e.pc, mode)
+		}
+		if e.fnFormatter != nil {
+			frame, _ := runtime.CallersFrames([]uintptr{e.pc}).Next()
+			return e.fnFormatter(frame)
+		}
+		return formatFunc(e.pc, e.fnFormat)
+	}
+	return e.fnName
+}
+
+func (e *SunError) GetErrorID() string {
+	if len(e.errorID) == 0 && e.hasRawID {
+		return hex.EncodeToString(e.rawID[:])
+	}
+	return e.errorID
+}
+
+// GetTraceID 链路追踪ID, 由WithTraceIDOption或SetTraceIDExtractor设置
+func (e *SunError) GetTraceID() string {
+	return e.traceID
+}
+
+// GetUserMsg 面向终端用户的提示, 由WithUserMsgOption或UserMsgTranslator设置
+func (e *SunError) GetUserMsg() string {
+	return e.userMsg
+}
+
+// GetDocsURL 错误文档链接, 由DocsLinkTranslator设置
+func (e *SunError) GetDocsURL() string {
+	return e.docsURL
+}
+
+// SyncExecutorErr 同步执行器返回的错误, 多个执行器失败时合并返回, 全部成功时为nil
+func (e *SunError) SyncExecutorErr() error {
+	return e.syncErr
+}
+
+// IsRetryable 调用方是否可以重试
+func (e *SunError) IsRetryable() bool {
+	return e.retryable
+}
+
+// HasSideEffect 失败的操作是否可能已经部分执行(如支付已提交但结果未知), 为true时不能直接重试
+func (e *SunError) HasSideEffect() bool {
+	return e.sideEffect
+}
+
+// GetRetryAfter 下游建议的重试间隔, 未设置时ok为false; Retry会优先使用该间隔
+func (e *SunError) GetRetryAfter() (time.Duration, bool) {
+	return e.retryAfter, e.hasRetryAfter
+}
+
+func (e *SunError) GetChannelCode() string {
 	return e.channelCode
 }
 
-func (e SunError) GetChannelMsg() string {
-	return e.channelMsg
+// GetChannelMsg 下游错误信息, 标记为PII时返回脱敏后的内容
+func (e *SunError) GetChannelMsg() string {
+	return e.mask(PIIChannelMsg, e.channelMsg)
 }
 
-func NewSunError(ctx context.Context, code, status, msg string, opts ...SunErrOption) *SunError {
-	sunErr := &SunError{
-		code:       code,
-		msg:        msg,
-		status:     status,
-		level:      ErrorLevel,
-		storeStack: true,
-		depth:      2,
-		stackRows:  10,
+// AppendDetail 返回追加了详细信息的副本, 原错误不变, 不会再次打印日志
+func (e *SunError) AppendDetail(format string, v ...interface{}) *SunError {
+	out := e.clone()
+	extra := fmt.Sprintf(format, v...)
+	if detail := e.rawDetail(); len(detail) == 0 {
+		out.detail = extra
+	} else {
+		out.detail = detail + "; " + extra
 	}
+	out.detail = truncate(out.detail, loadConfig().Limits.MaxDetail)
+	out.lazyDetail = nil
+	return out
+}
+
+func NewSunError(ctx context.Context, code Code, status, msg string, opts ...SunErrOption) *SunError {
+	sunErr := newSunError()
+	sunErr.init(ctx, string(code), status, msg, nil, opts)
+	return sunErr
+}
+
+// NewSunErrorAt 以pc作为调用点构造错误, 供代码生成器与已知调用点的封装函数使用, 不需要计算WithSkipDepthOption
+// pc为runtime.Callers或runtime.Caller得到的PC; 堆栈从pc所在的函数开始, 该函数不在当前调用栈中时只包含pc这一帧
+func NewSunErrorAt(ctx context.Context, pc uintptr, code Code, status, msg string, opts ...SunErrOption) *SunError {
+	sunErr := newSunError()
+	sunErr.pc = pc
+	sunErr.init(ctx, string(code), status, msg, nil, opts)
+	return sunErr
+}
+
+// init 初始化SunError并打印日志/执行执行器, 由NewSunError与AcquireSunError直接调用
+// depth以调用NewSunError的函数为准, 这里多了一层init的栈帧
+func (e *SunError) init(ctx context.Context, code, status, msg string, stackBuf *bytes.Buffer, opts []SunErrOption) {
+	e.code = code
+	e.msg = msg
+	e.status = status
+	cfg := loadConfig()
+	e.applyDefaults(cfg)
 	for _, opt := range opts {
-		opt(sunErr)
+		opt(e)
+	}
+	for _, opt := range contextOptions(ctx) {
+		opt(e)
 	}
 
-	if len(sunErr.fnName) == 0 {
-		sunErr.fnName = getCurrentFunc(sunErr.depth)
+	// 由NewSunErrorAt指定了调用点时不再按栈深度查找
+	pinned := e.pc != 0
+	if !pinned {
+		e.depth += helperSkip(e.depth + 1)
 	}
 
-	if sunErr.storeStack {
-		sunErr.stack = getStack(sunErr.depth, sunErr.stackRows)
+	// fnName与errorID只记录原始数据, 需要时再格式化, 不打印日志的错误构造时不产生额外的内存分配
+	if len(e.fnName) == 0 && !pinned {
+		e.pc = callerPC(e.depth + 1)
 	}
 
-	sunErr.ctxLog(ctx)
+	if strictMode.Load() {
+		e.validate(ctx, 2+cfg.SkipDepthBase)
+	}
 
-	if sunErr.asyncFn != nil {
-		sunErr.safeGo(ctx, func() {
-			sunErr.asyncFn(ctx, sunErr)
-		})
+	if len(e.errorID) == 0 {
+		newErrorID(&e.rawID)
+		e.hasRawID = true
+	}
+
+	if len(e.traceID) == 0 {
+		e.traceID = traceIDFromContext(ctx)
+	}
+
+	// 请求已经接近超时时不再抓取堆栈, 避免构造错误进一步拖慢请求
+	remaining, late := deadlineNear(ctx, cfg.DeadlineGuard)
+	if e.storeStack && late {
+		e.stack = []byte(skippedMarker("stack", remaining) + "\n")
+	} else if e.storeStack {
+		if stackBuf == nil {
+			stackBuf = new(bytes.Buffer)
+		}
+		if pinned {
+			e.stack, e.stackPCs = getStackFrom(stackBuf, e.depth+1, e.stackRows, e.pc)
+		} else {
+			e.stack, e.stackPCs = getStack(stackBuf, e.depth+1, e.stackRows)
+		}
+	}
+
+	e.decorate(ctx)
+	e.applyTenantPolicy(ctx)
+
+	if late && e.payload != nil {
+		e.payload = skippedPayload(e.payload, skippedMarker("payload", remaining))
+	}
+
+	if limits := cfg.Limits; limits != (Limits{}) {
+		e.applyLimits(limits)
+	}
+
+	e.internFields(cfg.InternStrings)
+
+	if e.pii != 0 {
+		e.auditMask(ctx)
+	}
+
+	if e.shouldLog(cfg) {
+		e.ctxLog(ctx)
+	}
+
+	recordError(ctx, e)
+	recordGroup(e)
+
+	if len(e.syncFns) > 0 {
+		e.runSync(ctx)
+	}
+
+	if e.level >= FatalLevel {
+		e.fatal()
+	}
+
+	if fns := e.executors(); len(fns) > 0 && e.sampleAsync() {
+		e.async = true
+		e.runAsync(ctx, fns)
 	}
-	return sunErr
 }
 
-// 异步执行并在发生panic后recover&打印堆栈
-func (e SunError) safeGo(ctx context.Context, f func()) {
-	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				buf := make([]byte, burSize)
-				buf = buf[:runtime.Stack(buf, false)]
-				e.logEngine(ctx, "SafeGo has panic:%s", string(buf))
-			}
-		}()
-		f()
+// 提交到异步执行池执行, 并在发生panic后recover&打印堆栈
+func (e *SunError) safeGo(ctx context.Context, f func()) {
+	submitAsync(ctx, e.level, func() {
+		e.safeCall(ctx, f)
+	})
+}
+
+// 同步执行并在发生panic后recover, 交给panic处理函数
+func (e *SunError) safeCall(ctx context.Context, f func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			bufp := panicStack()
+			e.handlePanic(ctx, r, *bufp)
+			putPanicStack(bufp)
+		}
 	}()
+	f()
 }
 
-// WithLogEngine 自定义的日志引擎 required
+// WithLogEngine 自定义的日志引擎, 未设置时使用Config.LogEngine, 两者都没有设置时不打印日志(严格模式下报告为误用)
 func WithLogEngine(log logFunc) SunErrOption {
 	return func(e *SunError) {
 		e.logEngine = log
 	}
 }
 
+// WithNoLogOption 构造时不打印日志, 如从下游响应还原SunError时使用
+func WithNoLogOption() SunErrOption {
+	return func(e *SunError) {
+		e.noLog = true
+	}
+}
+
 // WithLogLevelOption 设置日志打印等级, 不设置时默认为ErrorLevel
 func WithLogLevelOption(level SunErrLevel) SunErrOption {
 	return func(e *SunError) {
@@ -141,9 +510,12 @@ func WithLogLevelOption(level SunErrLevel) SunErrOption {
 }
 
 // WithDetailOption 设置报错详细信息, 如单号/Uid等参数
+// 只保存format与参数, 在打印日志/Error()/序列化时才格式化, 之后复用格式化结果;
+// 参数为指针等引用类型时, 格式化结果以首次格式化时的值为准
 func WithDetailOption(format string, v ...interface{}) SunErrOption {
 	return func(e *SunError) {
-		e.detail = fmt.Sprintf(format, v...)
+		e.detail = ""
+		e.lazyDetail = &lazyString{format: format, args: v}
 	}
 }
 
@@ -156,11 +528,18 @@ func WithFuncNameOption(funcName string) SunErrOption {
 
 // WithStackOption 设置是否保存函数栈信息, 不设置时默认保存
 func WithStackOption(storeStack bool) SunErrOption {
-	return func(e *SunError) {
-		e.storeStack = storeStack
+	if storeStack {
+		return withStack
 	}
+	return withoutStack
 }
 
+// 预先创建的无捕获闭包, 避免每次调用WithStackOption都分配闭包
+var (
+	withStack    SunErrOption = func(e *SunError) { e.storeStack = true }
+	withoutStack SunErrOption = func(e *SunError) { e.storeStack = false }
+)
+
 // WithSkipDepthOption 设置跳过的函数栈深度, 当你封装NewBizError时应该设置
 func WithSkipDepthOption(skipDepth int) SunErrOption {
 	return func(e *SunError) {
@@ -176,10 +555,121 @@ func WithChannelRespOption(channelCode, channelMsg string) SunErrOption {
 	}
 }
 
+// WithErrorIDOption 设置错误唯一ID, 不设置时自动生成; 从下游还原错误时可沿用原ID
+func WithErrorIDOption(errorID string) SunErrOption {
+	return func(e *SunError) {
+		e.errorID = errorID
+	}
+}
+
+// WithTraceIDOption 设置链路追踪ID, 不设置时由SetTraceIDExtractor设置的函数从ctx中获取
+func WithTraceIDOption(traceID string) SunErrOption {
+	return func(e *SunError) {
+		e.traceID = traceID
+	}
+}
+
+// WithRetryableOption 设置调用方是否可以重试, 不设置时默认不可重试
+func WithRetryableOption(retryable bool) SunErrOption {
+	return func(e *SunError) {
+		e.retryable = retryable
+	}
+}
+
+// WithSideEffectOption 标记失败的操作是否可能已经部分执行, 默认为false
+// 标记后IsRetryable(err)与Retry不再重试, 调用方需要先查询结果或按幂等键重放
+func WithSideEffectOption(performed bool) SunErrOption {
+	return func(e *SunError) {
+		e.sideEffect = performed
+	}
+}
+
+// WithRetryAfterOption 设置建议的重试间隔, 如下游返回的Retry-After/RetryInfo, 渲染响应时会原样带给调用方
+// 小于0时按0处理; 不会修改是否可重试, 需要时同时设置WithRetryableOption
+func WithRetryAfterOption(retryAfter time.Duration) SunErrOption {
+	return func(e *SunError) {
+		if retryAfter < 0 {
+			retryAfter = 0
+		}
+		e.retryAfter = retryAfter
+		e.hasRetryAfter = true
+	}
+}
+
+// WithUserMsgOption 设置面向终端用户的提示, msg仍用于日志与排查
+func WithUserMsgOption(userMsg string) SunErrOption {
+	return func(e *SunError) {
+		e.userMsg = userMsg
+	}
+}
+
+// WithDocsURLOption 设置错误文档链接, 通常由DocsLinkTranslator生成, 还原下游错误时使用
+func WithDocsURLOption(docsURL string) SunErrOption {
+	return func(e *SunError) {
+		e.docsURL = docsURL
+	}
+}
+
 // WithAsyncExecutor 产生错误后异步执行器, 如进行上报metrics打点
+// 可多次设置, 默认按设置顺序依次执行, 某个执行器panic不影响后续执行器
 func WithAsyncExecutor(fn func(context.Context, *SunError)) SunErrOption {
 	return func(e *SunError) {
-		e.asyncFn = fn
+		if fn != nil {
+			e.asyncFns = append(e.asyncFns, func(ctx context.Context, sunError *SunError) error {
+				fn(ctx, sunError)
+				return nil
+			})
+		}
+	}
+}
+
+// WithRetryableAsyncExecutor 可重试的异步执行器, 返回error时按WithAsyncRetry的设置重试, 如上报接口偶发失败
+func WithRetryableAsyncExecutor(fn func(context.Context, *SunError) error) SunErrOption {
+	return func(e *SunError) {
+		if fn != nil {
+			e.asyncFns = append(e.asyncFns, fn)
+		}
+	}
+}
+
+// WithSyncExecutor 同步执行器, 在NewSunError返回前执行, 如必须在响应前完成的补偿写入/审计记录
+// 执行超过timeout时不再等待(执行器应响应ctx的取消), 失败原因可通过SyncExecutorErr获取
+func WithSyncExecutor(fn func(context.Context, *SunError) error, timeout time.Duration) SunErrOption {
+	return func(e *SunError) {
+		if fn != nil {
+			e.syncFns = append(e.syncFns, syncExecutor{fn: fn, timeout: timeout})
+		}
+	}
+}
+
+// WithAsyncParentContext 异步执行器使用NewSunError传入的原始ctx, 原ctx取消时执行器收到的ctx也会取消
+// 默认执行器收到的ctx保留原ctx中的值但不继承取消信号
+func WithAsyncParentContext() SunErrOption {
+	return func(e *SunError) {
+		e.asyncCtx = true
+	}
+}
+
+// WithAsyncTimeout 设置单次异步执行的超时时间, 从传给执行器的ctx派生
+func WithAsyncTimeout(timeout time.Duration) SunErrOption {
+	return func(e *SunError) {
+		e.asyncTTL = timeout
+	}
+}
+
+// WithAsyncRetry 设置可重试异步执行器的最大尝试次数与首次重试前的等待时间(之后每次翻倍, 最短10ms), 默认不重试
+// 重试期间会占用异步执行池的工作协程
+func WithAsyncRetry(maxAttempts int, backoff time.Duration) SunErrOption {
+	return func(e *SunError) {
+		e.asyncTries = maxAttempts
+		e.asyncDelay = backoff
+	}
+}
+
+// WithParallelExecutors 异步执行器各自独立提交到异步执行池并行执行, 不保证执行顺序
+func WithParallelExecutors() SunErrOption {
+	return func(e *SunError) {
+		e.asyncPar = true
 	}
 }
 
@@ -194,40 +684,188 @@ func WithStackRows(stackRows int) SunErrOption {
 
 type logFunc func(ctx context.Context, format string, v ...interface{})
 
-func (e SunError) ctxLog(ctx context.Context) {
-	e.getLogFunc()(ctx, "%s", e.Error())
+// shouldLog 构造时是否打印日志: 没有设置WithNoLogOption, 不是DebugLevel或开启了EnableDebug, 且通过了日志采样
+func (e *SunError) shouldLog(cfg *config) bool {
+	return !e.noLog && (e.level > DebugLevel || cfg.EnableDebug) && e.sampleLog()
 }
 
-func (e SunError) getLogFunc() logFunc {
-	switch e.level {
-	case InfoLevel:
-		return e.logEngine
-	case WarnLevel:
-		return e.logEngine
-	case ErrorLevel:
+// ctxLog 打印错误, 参数直接传入错误本身, 格式化结果与Error()相同, 日志引擎也可以取出错误的字段
+// 对象池中的错误归还后会被复用, 传入格式化好的字符串, 避免异步写日志的引擎读到复用后的内容
+// 没有日志引擎时不打印, 严格模式下会作为误用报告
+func (e *SunError) ctxLog(ctx context.Context) {
+	log := e.getLogFunc()
+	if log == nil {
+		return
+	}
+	if e.pooled != nil {
+		log(ctx, "%s", e.Error())
+		return
+	}
+	log(ctx, "%s", e)
+}
+
+// getLogFunc 返回错误的日志引擎, 未设置时使用Config.LogEngine
+// Define的模板在包初始化时生成, 早于Configure, 这里在打印时再读取一次全局配置
+func (e *SunError) getLogFunc() logFunc {
+	if e.logEngine != nil {
 		return e.logEngine
 	}
-	return e.logEngine
+	return loadConfig().LogEngine
+}
+
+// newErrorID 生成8字节随机ID, 格式化后为16位十六进制
+func newErrorID(id *[8]byte) {
+	if _, err := rand.Read(id[:]); err != nil {
+		binary.BigEndian.PutUint64(id[:], uint64(time.Now().UnixNano()))
+	}
+}
+
+// callerPC 返回调用栈中跳过skip层后的调用点, skip的含义与runtime.Caller一致
+func callerPC(skip int) uintptr {
+	var pcs [1]uintptr
+	if runtime.Callers(skip+1, pcs[:]) == 0 {
+		return 0
+	}
+	return pcs[0]
+}
+
+// maxFuncNameCache fnName缓存的最大条目数, 超过后清空重建, 避免大量动态调用点时无限增长
+const maxFuncNameCache = 4096
+
+// funcNameKey fnName缓存的键, 同一调用点按不同格式分别缓存
+type funcNameKey struct {
+	pc     uintptr
+	format FuncFormat
+}
+
+var (
+	funcNameMu    sync.RWMutex
+	funcNameCache = make(map[funcNameKey]string)
+)
+
+// formatFunc 格式化调用点为 文件名:行号:函数名(), 同一调用点的结果按PC与格式缓存
+func formatFunc(pc uintptr, format FuncFormat) string {
+	key := funcNameKey{pc: pc, format: format}
+	funcNameMu.RLock()
+	name, ok := funcNameCache[key]
+	funcNameMu.RUnlock()
+	if ok {
+		return name
+	}
+	name = resolveFunc(pc, format)
+	funcNameMu.Lock()
+	if len(funcNameCache) >= maxFuncNameCache {
+		funcNameCache = make(map[funcNameKey]string)
+	}
+	funcNameCache[key] = name
+	funcNameMu.Unlock()
+	return name
 }
 
-func getCurrentFunc(skip int) string {
-	pc, file, line, ok := runtime.Caller(skip)
-	if !ok {
+func resolveFunc(pc uintptr, format FuncFormat) string {
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if len(frame.Function) == 0 {
 		return "??:0:??()"
 	}
-	funcName := runtime.FuncForPC(pc).Name()
-	funcName = strings.TrimLeft(filepath.Ext(funcName), ".") + "()"
-	return filepath.Base(file) + ":" + strconv.Itoa(line) + ":" + funcName
+	funcName := frame.Function + "()"
+	if format&FuncPackage == 0 {
+		funcName = strings.TrimLeft(filepath.Ext(frame.Function), ".") + "()"
+	}
+	file := frame.File
+	if format&FuncFullPath == 0 {
+		file = filepath.Base(file)
+	}
+	return file + ":" + strconv.Itoa(frame.Line) + ":" + funcName
+}
+
+// getStack 保存跳过skip层(含义与runtime.Caller一致)后的rows行调用栈, 同时返回这些PC
+// 一次runtime.Callers获取所有PC, 再由CallersFrames展开, 内联函数的栈帧也会被保留
+func getStack(buf *bytes.Buffer, skip, rows int) ([]byte, []uintptr) {
+	pcs := make([]uintptr, rows)
+	pcs = pcs[:runtime.Callers(skip+1, pcs)]
+	return writeFrames(buf, pcs, rows), pcs
+}
+
+// getStackFrom 与getStack相同, 但从pc所在的函数开始, 该函数的栈帧替换为pc; 当前调用栈中没有该函数时只输出pc这一帧
+func getStackFrom(buf *bytes.Buffer, skip, rows int, pc uintptr) ([]byte, []uintptr) {
+	var pcBuf [64]uintptr
+	n := runtime.Callers(skip+1, pcBuf[:])
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		for i, p := range pcBuf[:n] {
+			if f := runtime.FuncForPC(p - 1); f != nil && f.Entry() == fn.Entry() {
+				pcBuf[i] = pc
+				pcs := append([]uintptr(nil), pcBuf[i:n]...)
+				return writeFrames(buf, pcs, rows), pcs
+			}
+		}
+	}
+	pcs := []uintptr{pc}
+	return writeFrames(buf, pcs, 1), pcs
 }
 
-func getStack(skip, rows int) []byte {
-	buf := new(bytes.Buffer)
-	for i := skip; i-skip < rows; i++ {
-		pc, file, line, ok := runtime.Caller(i)
-		if !ok {
+// writeFrames 将PC展开为调用栈写入buf, 最多rows行; 设置了SetStackFormatter时按其格式输出
+func writeFrames(buf *bytes.Buffer, pcs []uintptr, rows int) []byte {
+	mode := getStackMode()
+	if len(pcs) == 0 || mode == StripStack {
+		return buf.Bytes()
+	}
+	if f := loadStackFormatter(); f != nil && mode == FullStack {
+		buf.Write(f.AppendStack(buf.AvailableBuffer(), stackFrames(pcs, rows)))
+		return buf.Bytes()
+	}
+	var num [20]byte
+	frames := runtime.CallersFrames(pcs)
+	for i := 0; i < rows; i++ {
+		frame, more := frames.Next()
+		if mode == StableStack {
+			buf.WriteString(filepath.Base(frame.File))
+			buf.WriteString(":LINE (PC)\n")
+		} else {
+			buf.WriteString(frame.File)
+			buf.WriteByte(':')
+			buf.Write(strconv.AppendInt(num[:0], int64(frame.Line), 10))
+			buf.WriteString(" (0x")
+			buf.Write(strconv.AppendUint(num[:0], uint64(frame.PC), 16))
+			buf.WriteString(")\n")
+		}
+		if !more {
 			break
 		}
-		fmt.Fprintf(buf, "%s:%d (0x%x)\n", file, line, pc)
 	}
 	return buf.Bytes()
 }
+
+// errorCache Error()结果的缓存
+type errorCache struct {
+	once sync.Once
+	s    string
+}
+
+// sunErrorBox 将SunError与其Error()缓存放在同一次内存分配中
+type sunErrorBox struct {
+	SunError
+	cache errorCache
+}
+
+func newSunError() *SunError {
+	b := new(sunErrorBox)
+	b.SunError.errCache = &b.cache
+	return &b.SunError
+}
+
+// lazyString 延迟格式化的字符串, 首次调用String时格式化并缓存, 副本之间共享同一缓存
+type lazyString struct {
+	once   sync.Once
+	format string
+	args   []interface{}
+	s      string
+	max    int // 格式化结果的最大字节数, 由Limits.MaxDetail设置
+}
+
+func (l *lazyString) String() string {
+	l.once.Do(func() {
+		l.s = truncate(fmt.Sprintf(l.format, l.args...), l.max)
+		l.args = nil
+	})
+	return l.s
+}
@@ -0,0 +1,227 @@
+package sunerror
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestNewSunErrorFastPathAllocs(t *testing.T) {
+	ctx := context.Background()
+	allocs := testing.AllocsPerRun(1000, func() {
+		_ = NewSunError(ctx, "FAST_1", "500", "fast path", WithStackOption(false), WithNoLogOption())
+	})
+	// 只有SunError结构体本身(与Error()缓存在同一次分配中)
+	if allocs > 1 {
+		t.Fatalf("NewSunError without stack and log allocates %v times, want 1", allocs)
+	}
+}
+
+func BenchmarkNewSunErrorFastPath(b *testing.B) {
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = NewSunError(ctx, "FAST_1", "500", "fast path", WithStackOption(false), WithNoLogOption())
+	}
+}
+
+func TestAcquireSunErrorAllocs(t *testing.T) {
+	ctx := context.Background()
+	allocs := testing.AllocsPerRun(1000, func() {
+		Release(AcquireSunError(ctx, "FAST_1", "500", "fast path", WithStackOption(false), WithNoLogOption()))
+	})
+	if allocs > 0 {
+		t.Fatalf("AcquireSunError/Release allocates %v times, want 0", allocs)
+	}
+}
+
+func BenchmarkAcquireSunErrorFastPath(b *testing.B) {
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Release(AcquireSunError(ctx, "FAST_1", "500", "fast path", WithStackOption(false), WithNoLogOption()))
+	}
+}
+
+// newStackError 保存32行堆栈的错误, 结构体复制的代价随堆栈与字段增大
+func newStackError() *SunError {
+	return NewSunError(context.Background(), "RECV_1", "500", "receiver", WithNoLogOption(), WithStackRows(32), WithDetailOption("order=%d", 42))
+}
+
+func TestGettersDoNotAllocate(t *testing.T) {
+	e := newStackError()
+	_ = e.Error()
+	allocs := testing.AllocsPerRun(1000, func() {
+		_ = e.GetCode()
+		_ = e.GetMsg()
+		_ = e.GetStatus()
+		_ = e.GetLevel()
+		_ = e.Error()
+	})
+	if allocs > 0 {
+		t.Fatalf("getters and cached Error() allocate %v times, want 0", allocs)
+	}
+}
+
+// benchSink 保存基准测试的结果, 避免调用被编译器优化掉
+var benchSink string
+
+func BenchmarkGetters(b *testing.B) {
+	e := newStackError()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchSink = e.GetCode().String()
+		benchSink = e.GetMsg()
+		benchSink = e.GetStatus()
+		benchSink = e.GetFnName()
+	}
+}
+
+func BenchmarkErrorCached(b *testing.B) {
+	e := newStackError()
+	_ = e.Error()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchSink = e.Error()
+	}
+}
+
+func TestAppendErrorMatchesError(t *testing.T) {
+	ctx := context.Background()
+	errs := []*SunError{
+		newStackError(),
+		NewSunError(ctx, "APPEND_1", "500", "a, b=c", WithNoLogOption(), WithStackOption(false), WithErrorIDOption("id 1")),
+		Wrap(ctx, newStackError(), "APPEND_2", "500", "wrap", WithNoLogOption()),
+	}
+	for _, e := range errs {
+		if got := string(e.AppendError(nil)); got != e.Error() {
+			t.Fatalf("AppendError = %q, Error() = %q", got, e.Error())
+		}
+	}
+}
+
+func TestAppendErrorDoesNotAllocate(t *testing.T) {
+	e := newStackError()
+	buf := make([]byte, 0, 4096)
+	if allocs := testing.AllocsPerRun(200, func() { buf = e.AppendError(buf[:0]) }); allocs > 0 {
+		t.Fatalf("AppendError allocates %v times, want 0", allocs)
+	}
+}
+
+// adversarialValues 会破坏按分隔符切分的日志解析器的字段值
+var adversarialValues = []string{
+	"",
+	"plain",
+	"a=b",
+	"a, b",
+	"code=X, msg=Y",
+	"line1\nline2",
+	"tab\tcr\r",
+	"ls\u2028ps\u2029nel\u0085",
+	`quote" back\slash`,
+	"\xff\xfe invalid utf8",
+	"] code=FAKE",
+	"中文, 😀=1",
+}
+
+// parseErrorFields 按Error()的格式解析第一行中的key=value, 带引号的值按Go语法还原
+func parseErrorFields(t *testing.T, line string) map[string]string {
+	t.Helper()
+	_, rest, ok := strings.Cut(line, "] ")
+	if !ok {
+		t.Fatalf("missing fnName prefix: %q", line)
+	}
+	fields := make(map[string]string)
+	for len(rest) > 0 {
+		key, value, ok := strings.Cut(rest, "=")
+		if !ok {
+			t.Fatalf("missing '=' in %q", rest)
+		}
+		if strings.HasPrefix(value, `"`) {
+			quoted, err := strconv.QuotedPrefix(value)
+			if err != nil {
+				t.Fatalf("bad quoted value %q: %v", value, err)
+			}
+			fields[key], _ = strconv.Unquote(quoted)
+			rest = value[len(quoted):]
+		} else {
+			end := strings.Index(value, ", ")
+			if end < 0 {
+				end = len(value)
+			}
+			fields[key] = value[:end]
+			rest = value[end:]
+		}
+		rest = strings.TrimPrefix(rest, ", ")
+	}
+	return fields
+}
+
+func TestErrorQuotesAdversarialValues(t *testing.T) {
+	ctx := context.Background()
+	for _, v := range adversarialValues {
+		e := NewSunError(ctx, "QUOTE_1", "500", v, WithNoLogOption(), WithStackOption(false),
+			WithDetailOption("%s", v), WithChannelRespOption(v, v))
+		out := e.Error()
+		if strings.ContainsAny(out, "\n\r\u2028\u2029\u0085") {
+			t.Fatalf("Error() spans lines for %q: %q", v, out)
+		}
+		fields := parseErrorFields(t, out)
+		for _, key := range []string{"msg", "detail", "channelCode", "channelMsg"} {
+			if fields[key] != v {
+				t.Fatalf("%s = %q, want %q\nError() = %q", key, fields[key], v, out)
+			}
+		}
+		if fields["code"] != "QUOTE_1" || fields["errorID"] != e.GetErrorID() {
+			t.Fatalf("fields misaligned for %q: %q", v, out)
+		}
+	}
+}
+
+func TestErrorQuotesNestedCause(t *testing.T) {
+	ctx := context.Background()
+	for _, v := range adversarialValues {
+		inner := NewSunError(ctx, "QUOTE_INNER", "500", v, WithNoLogOption(), WithStackOption(false), WithDetailOption("%s", v))
+		middle := fmt.Errorf("call %q: %w", v, Wrap(ctx, inner, "QUOTE_MID", "500", v, WithNoLogOption(), WithStackOption(false)))
+		outer := Wrap(ctx, middle, "QUOTE_OUTER", "500", "outer", WithNoLogOption(), WithStackOption(false))
+		out := outer.Error()
+		if strings.ContainsAny(out, "\n\u2028") {
+			t.Fatalf("Error() spans lines: %q", out)
+		}
+		fields := parseErrorFields(t, out)
+		if fields["cause"] != middle.Error() {
+			t.Fatalf("cause = %q, want %q", fields["cause"], middle.Error())
+		}
+		if got := string(outer.AppendError(nil)); got != out {
+			t.Fatalf("AppendError = %q, Error() = %q", got, out)
+		}
+	}
+}
+
+func TestMarshalJSONAdversarialValues(t *testing.T) {
+	ctx := context.Background()
+	for _, v := range adversarialValues {
+		e := NewSunError(ctx, "QUOTE_2", "500", v, WithNoLogOption(), WithStackOption(false), WithDetailOption("%s", v))
+		data, err := json.Marshal(e)
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		if strings.ContainsAny(string(data), "\n\u2028\u2029") {
+			t.Fatalf("JSON spans lines for %q: %s", v, data)
+		}
+		var decoded struct {
+			Msg string `json:"msg"`
+		}
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("Unmarshal %s: %v", data, err)
+		}
+		// 非法UTF-8被encoding/json替换为U+FFFD
+		if utf8.ValidString(v) && decoded.Msg != v {
+			t.Fatalf("msg = %q, want %q", decoded.Msg, v)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Package sunerrorpb 定义SunError的protobuf消息(sunerror.proto), 并提供与SunError之间的转换
+// 单独成包以保证sunerror本身只依赖标准库
+package sunerrorpb
+
+//go:generate protoc -I.. --go_out=.. --go_opt=paths=source_relative ../sunerrorpb/sunerror.proto
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/sjmshsh/sunerror"
+)
+
+// ToProto 将SunError转换为protobuf消息, 包括fnName与堆栈, e为nil时返回nil
+// 对外响应请先调用External, 避免泄露函数名与堆栈
+func ToProto(e *sunerror.SunError) *Error {
+	if e == nil {
+		return nil
+	}
+	r := e.ToRecord(true)
+	m := &Error{
+		Code:            r.Code,
+		Msg:             r.Msg,
+		Status:          r.Status,
+		Detail:          r.Detail,
+		Level:           int32(r.Level),
+		FnName:          r.FnName,
+		ChannelCode:     r.ChannelCode,
+		ChannelMsg:      r.ChannelMsg,
+		ErrorId:         r.ErrorID,
+		TraceId:         r.TraceID,
+		Request:         r.Request,
+		Response:        r.Response,
+		ChannelEndpoint: r.ChannelEndpoint,
+		ChannelLatency:  r.ChannelLatency,
+		ChannelAttempt:  int32(r.ChannelAttempt),
+		UserMsg:         r.UserMsg,
+		DocsUrl:         r.DocsURL,
+		Kind:            r.Kind,
+		Retryable:       r.Retryable,
+		RetryAfter:      r.RetryAfter,
+		SideEffect:      r.SideEffect,
+		Cause:           r.Cause,
+		Stack:           parseStack(r.Stack),
+		RemoteFnName:    r.RemoteFnName,
+		RemoteStack:     r.RemoteStack,
+	}
+	for _, v := range r.Violations {
+		m.Violations = append(m.Violations, &FieldViolation{Field: v.Field, Rule: v.Rule, Message: v.Message})
+	}
+	return m
+}
+
+// FromProto 从protobuf消息还原SunError, 还原时不打印日志也不执行执行器
+// m为nil时返回nil; retry_after或channel_latency不是合法的时长时返回错误
+func FromProto(m *Error) (*sunerror.SunError, error) {
+	if m == nil {
+		return nil, nil
+	}
+	r := sunerror.Record{
+		Code:            m.GetCode(),
+		Status:          m.GetStatus(),
+		Msg:             m.GetMsg(),
+		Detail:          m.GetDetail(),
+		FnName:          m.GetFnName(),
+		ChannelCode:     m.GetChannelCode(),
+		ChannelMsg:      m.GetChannelMsg(),
+		ErrorID:         m.GetErrorId(),
+		TraceID:         m.GetTraceId(),
+		Request:         m.GetRequest(),
+		Response:        m.GetResponse(),
+		ChannelEndpoint: m.GetChannelEndpoint(),
+		ChannelLatency:  m.GetChannelLatency(),
+		ChannelAttempt:  int(m.GetChannelAttempt()),
+		UserMsg:         m.GetUserMsg(),
+		DocsURL:         m.GetDocsUrl(),
+		Kind:            m.GetKind(),
+		Retryable:       m.GetRetryable(),
+		RetryAfter:      m.GetRetryAfter(),
+		SideEffect:      m.GetSideEffect(),
+		Level:           sunerror.SunErrLevel(m.GetLevel()),
+		Cause:           m.GetCause(),
+		Stack:           formatStack(m.GetStack()),
+		RemoteFnName:    m.GetRemoteFnName(),
+		RemoteStack:     m.GetRemoteStack(),
+	}
+	for _, v := range m.GetViolations() {
+		r.Violations = append(r.Violations, sunerror.FieldViolation{Field: v.GetField(), Rule: v.GetRule(), Message: v.GetMessage()})
+	}
+	return sunerror.FromRecord(r)
+}
+
+// parseStack 将"file:line (0xpc)"格式的堆栈文本拆分为栈帧
+// StableStack模式的"file:LINE (PC)"解析为line与pc都为0的栈帧
+func parseStack(stack string) []*StackFrame {
+	if len(stack) == 0 {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSuffix(stack, "\n"), "\n")
+	frames := make([]*StackFrame, 0, len(lines))
+	for _, l := range lines {
+		frame := &StackFrame{File: l}
+		if i := strings.LastIndex(l, " ("); i >= 0 {
+			pc := strings.TrimSuffix(l[i+2:], ")")
+			frame.Pc, _ = strconv.ParseUint(strings.TrimPrefix(pc, "0x"), 16, 64)
+			l = l[:i]
+		}
+		if i := strings.LastIndexByte(l, ':'); i >= 0 {
+			line, _ := strconv.Atoi(l[i+1:])
+			frame.File, frame.Line = l[:i], int32(line)
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+// formatStack parseStack的逆操作
+func formatStack(frames []*StackFrame) string {
+	var b strings.Builder
+	for _, f := range frames {
+		b.WriteString(f.GetFile())
+		if f.GetLine() == 0 && f.GetPc() == 0 {
+			b.WriteString(":LINE (PC)\n")
+			continue
+		}
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(int(f.GetLine())))
+		b.WriteString(" (0x")
+		b.WriteString(strconv.FormatUint(f.GetPc(), 16))
+		b.WriteString(")\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,126 @@
+// Package sunerrortest 提供单元测试中使用的SunError断言与匹配工具, 避免测试直接匹配err.Error()的字符串
+package sunerrortest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/sjmshsh/sunerror"
+)
+
+// AssertCode 断言err(或其错误链中)是code为指定值的SunError, 失败时输出期望值与实际值
+func AssertCode(t testing.TB, err error, code sunerror.Code) bool {
+	t.Helper()
+	return assert(t, err, HasCode(code), func(e *sunerror.SunError) (interface{}, interface{}) {
+		return fmt.Sprintf("%q", code), fmt.Sprintf("%q", e.GetCode())
+	})
+}
+
+// AssertKind 断言err(或其错误链中)是分类为指定值的SunError
+func AssertKind(t testing.TB, err error, kind sunerror.SunErrKind) bool {
+	t.Helper()
+	return assert(t, err, HasKind(kind), func(e *sunerror.SunError) (interface{}, interface{}) {
+		return kind, e.GetKind()
+	})
+}
+
+// AssertLevel 断言err(或其错误链中)是日志等级为指定值的SunError
+func AssertLevel(t testing.TB, err error, level sunerror.SunErrLevel) bool {
+	t.Helper()
+	return assert(t, err, HasLevel(level), func(e *sunerror.SunError) (interface{}, interface{}) {
+		return level, e.GetLevel()
+	})
+}
+
+// AssertStatus 断言err(或其错误链中)是status为指定值的SunError
+func AssertStatus(t testing.TB, err error, status string) bool {
+	t.Helper()
+	return assert(t, err, HasStatus(status), func(e *sunerror.SunError) (interface{}, interface{}) {
+		return fmt.Sprintf("%q", status), fmt.Sprintf("%q", e.GetStatus())
+	})
+}
+
+// AssertEqual 断言两个错误的数据字段一致(见sunerror.Equal), 用于校验序列化再还原后没有丢失字段
+// 失败时以JSON输出两者的全部数据字段
+func AssertEqual(t testing.TB, expected, actual *sunerror.SunError) bool {
+	t.Helper()
+	if sunerror.Equal(expected, actual) {
+		return true
+	}
+	e, _ := json.Marshal(sunerror.Normalize(expected))
+	a, _ := json.Marshal(sunerror.Normalize(actual))
+	t.Errorf("sunerrortest: SunError fields mismatch\n\texpected: %s\n\tactual:   %s", e, a)
+	return false
+}
+
+func assert(t testing.TB, err error, m Matcher, values func(e *sunerror.SunError) (interface{}, interface{})) bool {
+	t.Helper()
+	var e *sunerror.SunError
+	if !errors.As(err, &e) {
+		t.Errorf("sunerrortest: expected *sunerror.SunError %s\n\tactual: %T(%v)", m.desc, err, err)
+		return false
+	}
+	if m.match(e) {
+		return true
+	}
+	expected, actual := values(e)
+	t.Errorf("sunerrortest: SunError mismatch\n\texpected: %v\n\tactual:   %v\n\terror:    %s", expected, actual, e.Error())
+	return false
+}
+
+// Matcher SunError匹配器, 可用于testify与gomock:
+//
+//	assert.True(t, sunerrortest.HasCode("PAY_1001").Match(err))
+//	m.On("Report", mock.MatchedBy(sunerrortest.HasCode("PAY_1001").Match))
+//	mockObj.EXPECT().Report(sunerrortest.HasCode("PAY_1001"))
+type Matcher struct {
+	desc  string
+	match func(e *sunerror.SunError) bool
+}
+
+// HasCode 匹配code为指定值的SunError
+func HasCode(code sunerror.Code) Matcher {
+	return Matcher{desc: fmt.Sprintf("with code %q", code), match: func(e *sunerror.SunError) bool {
+		return e.GetCode() == code
+	}}
+}
+
+// HasKind 匹配分类为指定值的SunError
+func HasKind(kind sunerror.SunErrKind) Matcher {
+	return Matcher{desc: fmt.Sprintf("with kind %v", kind), match: func(e *sunerror.SunError) bool {
+		return e.GetKind() == kind
+	}}
+}
+
+// HasLevel 匹配日志等级为指定值的SunError
+func HasLevel(level sunerror.SunErrLevel) Matcher {
+	return Matcher{desc: fmt.Sprintf("with level %v", level), match: func(e *sunerror.SunError) bool {
+		return e.GetLevel() == level
+	}}
+}
+
+// HasStatus 匹配status为指定值的SunError
+func HasStatus(status string) Matcher {
+	return Matcher{desc: fmt.Sprintf("with status %q", status), match: func(e *sunerror.SunError) bool {
+		return e.GetStatus() == status
+	}}
+}
+
+// Match 判断err(或其错误链中)是否存在满足条件的SunError
+func (m Matcher) Match(err error) bool {
+	var e *sunerror.SunError
+	return errors.As(err, &e) && m.match(e)
+}
+
+// Matches 实现gomock.Matcher, x不是error时返回false
+func (m Matcher) Matches(x interface{}) bool {
+	err, ok := x.(error)
+	return ok && m.Match(err)
+}
+
+// String 实现gomock.Matcher与fmt.Stringer, 用于打印匹配失败时的期望值
+func (m Matcher) String() string {
+	return "*sunerror.SunError " + m.desc
+}
@@ -0,0 +1,143 @@
+package sunerrortest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/sjmshsh/sunerror"
+)
+
+// Entry 捕获到的一条日志
+type Entry struct {
+	Code    sunerror.Code        // 错误码, 非SunError的日志(如异步执行器失败)为空
+	Level   sunerror.SunErrLevel // 日志等级, 非SunError的日志为零值
+	Message string               // 格式化后的日志内容
+	Fields  map[string]string    // SunError的其余字段: status, msg, detail, fnName, channelCode, channelMsg, errorID, kind
+	Err     *sunerror.SunError   // 产生日志的错误, 非SunError的日志为nil
+}
+
+// Capture 内存中的日志引擎, 记录所有打印过的日志供测试断言, 可以并发使用
+//
+//	c := sunerrortest.NewCapture()
+//	err := sunerror.NewSunError(ctx, "PAY_1001", "1", "pay failed", c.Option())
+//	c.AssertLoggedOnce(t, "PAY_1001")
+type Capture struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// NewCapture 创建日志捕获器, 通过c.Log或c.Option()作为日志引擎传给SunError
+func NewCapture() *Capture {
+	return &Capture{}
+}
+
+// Log 日志引擎, 可直接传给sunerror.WithLogEngine
+func (c *Capture) Log(ctx context.Context, format string, v ...interface{}) {
+	entry := Entry{Message: fmt.Sprintf(format, v...)}
+	for _, arg := range v {
+		err, ok := arg.(error)
+		if !ok {
+			continue
+		}
+		var e *sunerror.SunError
+		if errors.As(err, &e) {
+			entry.Code = e.GetCode()
+			entry.Level = e.GetLevel()
+			entry.Err = e
+			entry.Fields = map[string]string{
+				"status":      e.GetStatus(),
+				"msg":         e.GetMsg(),
+				"detail":      e.GetDetail(),
+				"fnName":      e.GetFnName(),
+				"channelCode": e.GetChannelCode(),
+				"channelMsg":  e.GetChannelMsg(),
+				"errorID":     e.GetErrorID(),
+				"kind":        e.GetKind().String(),
+			}
+			break
+		}
+	}
+	c.mu.Lock()
+	c.entries = append(c.entries, entry)
+	c.mu.Unlock()
+}
+
+// Option 返回使用该捕获器作为日志引擎的SunErrOption
+func (c *Capture) Option() sunerror.SunErrOption {
+	return sunerror.WithLogEngine(c.Log)
+}
+
+// Entries 返回已捕获日志的副本, 按打印顺序排列
+func (c *Capture) Entries() []Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Entry(nil), c.entries...)
+}
+
+// ByCode 返回错误码为code的日志
+func (c *Capture) ByCode(code sunerror.Code) []Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var out []Entry
+	for _, entry := range c.entries {
+		if entry.Code == code {
+			out = append(out, entry)
+		}
+	}
+	return out
+}
+
+// Count 返回错误码为code的日志条数
+func (c *Capture) Count(code sunerror.Code) int {
+	return len(c.ByCode(code))
+}
+
+// Reset 清空已捕获的日志
+func (c *Capture) Reset() {
+	c.mu.Lock()
+	c.entries = nil
+	c.mu.Unlock()
+}
+
+// AssertLogged 断言错误码为code的日志至少打印过一次
+func (c *Capture) AssertLogged(t testing.TB, code sunerror.Code) bool {
+	t.Helper()
+	if c.Count(code) == 0 {
+		t.Errorf("sunerrortest: expected error %q to be logged\n\tcaptured: %q", code, c.codes())
+		return false
+	}
+	return true
+}
+
+// AssertLoggedOnce 断言错误码为code的日志恰好打印过一次
+func (c *Capture) AssertLoggedOnce(t testing.TB, code sunerror.Code) bool {
+	t.Helper()
+	if n := c.Count(code); n != 1 {
+		t.Errorf("sunerrortest: expected error %q to be logged once, logged %d times\n\tcaptured: %q", code, n, c.codes())
+		return false
+	}
+	return true
+}
+
+// AssertNotLogged 断言错误码为code的日志没有打印过
+func (c *Capture) AssertNotLogged(t testing.TB, code sunerror.Code) bool {
+	t.Helper()
+	if n := c.Count(code); n != 0 {
+		t.Errorf("sunerrortest: expected error %q not to be logged, logged %d times", code, n)
+		return false
+	}
+	return true
+}
+
+func (c *Capture) codes() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	codes := make([]string, 0, len(c.entries))
+	for _, entry := range c.entries {
+		codes = append(codes, string(entry.Code))
+	}
+	return codes
+}
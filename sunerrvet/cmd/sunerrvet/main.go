@@ -0,0 +1,14 @@
+// Command sunerrvet 运行sunerrvet中的全部分析器
+//
+//	go install github.com/sjmshsh/sunerror/sunerrvet/cmd/sunerrvet@latest
+//	sunerrvet ./...
+package main
+
+import (
+	"github.com/sjmshsh/sunerror/sunerrvet"
+	"golang.org/x/tools/go/analysis/multichecker"
+)
+
+func main() {
+	multichecker.Main(sunerrvet.Analyzer, sunerrvet.MisuseAnalyzer)
+}
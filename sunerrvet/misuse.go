@@ -0,0 +1,244 @@
+package sunerrvet
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// MisuseAnalyzer 检查sunerror的常见误用
+// 1. main包及其依赖都没有设置全局日志引擎(Configure/AddDefaultOptions/UseProfile等), 而NewSunError没有传WithLogEngine, 错误不会被打印
+// 2. 以包装函数的参数作为错误码构造错误, 但没有传WithSkipDepthOption也没有调用MarkHelper, fnName与堆栈会指向包装函数而不是它的调用方
+// 3. 丢弃构造出的错误, 如单独一行NewSunError(...)或赋值给_
+// 4. 用==/!=比较*SunError, 比较的是指针而不是错误码
+var MisuseAnalyzer = &analysis.Analyzer{
+	Name:      "sunerrmisuse",
+	Doc:       "report common misuse of sunerror: missing log engine, wrappers without skip depth, discarded errors and == comparison",
+	Requires:  []*analysis.Analyzer{inspect.Analyzer},
+	Run:       runMisuse,
+	FactTypes: []analysis.Fact{new(configuresDefaults)},
+}
+
+// configuresDefaults 包中调用了设置全局日志引擎的函数
+type configuresDefaults struct{}
+
+func (*configuresDefaults) AFact() {}
+
+func (*configuresDefaults) String() string {
+	return "configuresDefaults"
+}
+
+// configFuncs 可以设置全局日志引擎的函数
+var configFuncs = map[string]bool{
+	"Configure":         true,
+	"AddDefaultOptions": true,
+	"UseProfile":        true,
+	"UseProfileFromEnv": true,
+	"ApplySettings":     true,
+}
+
+// constructorFuncs 返回新错误的函数, 结果不应被丢弃
+var constructorFuncs = map[string]bool{
+	"NewSunError":      true,
+	"NewSunErrorAt":    true,
+	"AcquireSunError":  true,
+	"Wrap":             true,
+	"AggregateErrors":  true,
+	"FromError":        true,
+	"FromDBError":      true,
+	"FromContextError": true,
+	"New":              true,
+	"Newf":             true,
+}
+
+// wrapperFuncs 按调用栈深度确定fnName的构造函数, 包装时需要WithSkipDepthOption
+var wrapperFuncs = map[string]bool{
+	"NewSunError":     true,
+	"AcquireSunError": true,
+	"Wrap":            true,
+}
+
+func runMisuse(pass *analysis.Pass) (interface{}, error) {
+	configured := len(pass.AllPackageFacts()) > 0
+	var noEngine []*ast.CallExpr
+
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	nodes := []ast.Node{(*ast.CallExpr)(nil), (*ast.ExprStmt)(nil), (*ast.AssignStmt)(nil), (*ast.BinaryExpr)(nil)}
+	inspect.WithStack(nodes, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			name := sunerrorFunc(pass.TypesInfo, n)
+			if configFuncs[name] {
+				configured = true
+			}
+			if name == "NewSunError" && !hasOption(pass.TypesInfo, n, "WithLogEngine") {
+				noEngine = append(noEngine, n)
+			}
+			if wrapperFuncs[name] {
+				checkWrapper(pass, n, name, stack)
+			}
+		case *ast.ExprStmt:
+			if call, ok := n.X.(*ast.CallExpr); ok && isConstructor(pass.TypesInfo, call) {
+				pass.Reportf(call.Pos(), "result of %s is discarded", calleeName(pass.TypesInfo, call))
+			}
+		case *ast.AssignStmt:
+			if len(n.Rhs) != 1 || !allBlank(n.Lhs) {
+				break
+			}
+			if call, ok := n.Rhs[0].(*ast.CallExpr); ok && isConstructor(pass.TypesInfo, call) {
+				pass.Reportf(call.Pos(), "result of %s is discarded", calleeName(pass.TypesInfo, call))
+			}
+		case *ast.BinaryExpr:
+			checkCompare(pass, n)
+		}
+		return true
+	})
+
+	if configured {
+		pass.ExportPackageFact(&configuresDefaults{})
+	} else if pass.Pkg.Name() == "main" {
+		for _, call := range noEngine {
+			pass.Reportf(call.Pos(), "NewSunError without WithLogEngine and no default log engine is configured; the error will not be logged")
+		}
+	}
+	return nil, nil
+}
+
+// checkWrapper 以外层函数的参数作为错误码时, 外层函数应传WithSkipDepthOption或调用MarkHelper
+func checkWrapper(pass *analysis.Pass, call *ast.CallExpr, name string, stack []ast.Node) {
+	i := codeArgs[name]
+	if i >= len(call.Args) {
+		return
+	}
+	ident, ok := call.Args[i].(*ast.Ident)
+	if !ok {
+		return
+	}
+	fn := enclosingFunc(stack)
+	if fn == nil || !isParam(pass.TypesInfo, fn, ident) || hasOption(pass.TypesInfo, call, "WithSkipDepthOption") || callsMarkHelper(pass.TypesInfo, fn) {
+		return
+	}
+	pass.Reportf(call.Pos(), "%s in a wrapper function without WithSkipDepthOption or sunerror.MarkHelper; fnName will point at the wrapper", name)
+}
+
+// checkCompare 用==/!=比较*SunError(与nil比较除外)
+func checkCompare(pass *analysis.Pass, b *ast.BinaryExpr) {
+	if b.Op != token.EQL && b.Op != token.NEQ {
+		return
+	}
+	if isNil(pass.TypesInfo, b.X) || isNil(pass.TypesInfo, b.Y) {
+		return
+	}
+	if isSunErrorPtr(pass.TypesInfo.TypeOf(b.X)) || isSunErrorPtr(pass.TypesInfo.TypeOf(b.Y)) {
+		pass.Reportf(b.OpPos, "comparing *sunerror.SunError with %s compares pointers; compare GetCode() or use sunerror.Equal", b.Op)
+	}
+}
+
+// hasOption call的参数中是否直接调用了sunerror的name选项
+func hasOption(info *types.Info, call *ast.CallExpr, name string) bool {
+	for _, arg := range call.Args {
+		if c, ok := arg.(*ast.CallExpr); ok && sunerrorFunc(info, c) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isConstructor call是否返回新错误: constructorFuncs中的函数与Definition的New/Wrap方法
+func isConstructor(info *types.Info, call *ast.CallExpr) bool {
+	if constructorFuncs[sunerrorFunc(info, call)] {
+		return true
+	}
+	fn, ok := typeutil.Callee(info, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != pkgPath || (fn.Name() != "New" && fn.Name() != "Wrap") {
+		return false
+	}
+	sig, ok := fn.Type().(*types.Signature)
+	return ok && sig.Recv() != nil && isNamed(sig.Recv().Type(), "Definition")
+}
+
+func calleeName(info *types.Info, call *ast.CallExpr) string {
+	if obj := typeutil.Callee(info, call); obj != nil {
+		return obj.Name()
+	}
+	return "call"
+}
+
+func callsMarkHelper(info *types.Info, fn *ast.FuncDecl) bool {
+	found := false
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok && sunerrorFunc(info, call) == "MarkHelper" {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// enclosingFunc 最内层的具名函数, 在函数字面量中时返回nil
+func enclosingFunc(stack []ast.Node) *ast.FuncDecl {
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch n := stack[i].(type) {
+		case *ast.FuncLit:
+			return nil
+		case *ast.FuncDecl:
+			return n
+		}
+	}
+	return nil
+}
+
+func isParam(info *types.Info, fn *ast.FuncDecl, ident *ast.Ident) bool {
+	obj := info.Uses[ident]
+	if obj == nil || fn.Type.Params == nil {
+		return false
+	}
+	for _, field := range fn.Type.Params.List {
+		for _, name := range field.Names {
+			if info.Defs[name] == obj {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func allBlank(exprs []ast.Expr) bool {
+	for _, e := range exprs {
+		if ident, ok := e.(*ast.Ident); !ok || ident.Name != "_" {
+			return false
+		}
+	}
+	return true
+}
+
+func isNil(info *types.Info, e ast.Expr) bool {
+	tv, ok := info.Types[e]
+	return ok && tv.IsNil()
+}
+
+func isSunErrorPtr(t types.Type) bool {
+	p, ok := t.(*types.Pointer)
+	return ok && isNamed(p.Elem(), "SunError")
+}
+
+// isNamed t是否为sunerror包中名为name的类型或其指针
+func isNamed(t types.Type, name string) bool {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == pkgPath && obj.Name() == name
+}
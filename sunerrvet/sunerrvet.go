@@ -0,0 +1,171 @@
+// Package sunerrvet 提供检查SunError错误码登记情况与常见误用的go/analysis分析器
+// 通过cmd/sunerrvet单独运行, 或以go vet -vettool=$(which sunerrvet) ./...运行
+package sunerrvet
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// pkgPath sunerror包的导入路径
+const pkgPath = "github.com/sjmshsh/sunerror"
+
+// Analyzer 检查错误码的登记情况
+// 1. 以常量错误码调用NewSunError/NewSunErrorAt/AcquireSunError/Wrap/AggregateErrors, 而该错误码没有在本包或依赖的包中通过sunerror.Define登记
+// 2. 包级的未导出变量保存了sunerror.Define的结果, 但该变量从未被引用, 本包中也没有以该错误码构造错误
+// 导出的定义可能被其他包使用, 不检查
+var Analyzer = &analysis.Analyzer{
+	Name:      "sunerrvet",
+	Doc:       "report sunerror codes constructed without sunerror.Define and registered codes that are never used",
+	Requires:  []*analysis.Analyzer{inspect.Analyzer},
+	Run:       run,
+	FactTypes: []analysis.Fact{new(definedCodes)},
+}
+
+// definedCodes 包中通过sunerror.Define登记的错误码, 供依赖该包的包检查
+type definedCodes struct {
+	Codes []string
+}
+
+func (*definedCodes) AFact() {}
+
+func (f *definedCodes) String() string {
+	return "definedCodes(" + strings.Join(f.Codes, ", ") + ")"
+}
+
+// codeArgs 以错误码为参数构造错误的函数及错误码参数的位置
+var codeArgs = map[string]int{
+	"NewSunError":     1,
+	"NewSunErrorAt":   2,
+	"AcquireSunError": 1,
+	"Wrap":            2,
+	"AggregateErrors": 2,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	registered := make(map[string]bool)
+	for _, f := range pass.AllPackageFacts() {
+		if d, ok := f.Fact.(*definedCodes); ok {
+			for _, code := range d.Codes {
+				registered[code] = true
+			}
+		}
+	}
+
+	var local []string
+	var constructed []*ast.CallExpr
+	used := make(map[string]bool)
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	inspect.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		name := sunerrorFunc(pass.TypesInfo, call)
+		if name == "Define" {
+			if code, ok := constString(pass.TypesInfo, call.Args[0]); ok {
+				local = append(local, code)
+				registered[code] = true
+			}
+			return
+		}
+		i, ok := codeArgs[name]
+		if !ok || i >= len(call.Args) {
+			return
+		}
+		if code, ok := constString(pass.TypesInfo, call.Args[i]); ok {
+			used[code] = true
+			constructed = append(constructed, call)
+		}
+	})
+
+	for _, call := range constructed {
+		arg := call.Args[codeArgs[sunerrorFunc(pass.TypesInfo, call)]]
+		code, _ := constString(pass.TypesInfo, arg)
+		if !registered[code] {
+			pass.Reportf(arg.Pos(), "sunerror code %q is not registered with sunerror.Define", code)
+		}
+	}
+
+	for obj, def := range unexportedDefinitions(pass) {
+		if !used[def.code] && !isReferenced(pass.TypesInfo, obj) {
+			pass.Reportf(def.pos.Pos(), "sunerror code %q is registered by %s but never used", def.code, obj.Name())
+		}
+	}
+
+	if len(local) > 0 {
+		sort.Strings(local)
+		pass.ExportPackageFact(&definedCodes{Codes: local})
+	}
+	return nil, nil
+}
+
+type definition struct {
+	code string
+	pos  ast.Node
+}
+
+// unexportedDefinitions 包级未导出变量中保存的sunerror.Define的结果
+func unexportedDefinitions(pass *analysis.Pass) map[types.Object]definition {
+	defs := make(map[types.Object]definition)
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs, ok := spec.(*ast.ValueSpec)
+				if !ok || len(vs.Names) != len(vs.Values) {
+					continue
+				}
+				for i, name := range vs.Names {
+					call, ok := vs.Values[i].(*ast.CallExpr)
+					if !ok || name.IsExported() || name.Name == "_" || sunerrorFunc(pass.TypesInfo, call) != "Define" {
+						continue
+					}
+					code, ok := constString(pass.TypesInfo, call.Args[0])
+					if obj := pass.TypesInfo.Defs[name]; ok && obj != nil {
+						defs[obj] = definition{code: code, pos: name}
+					}
+				}
+			}
+		}
+	}
+	return defs
+}
+
+func isReferenced(info *types.Info, obj types.Object) bool {
+	for _, used := range info.Uses {
+		if used == obj {
+			return true
+		}
+	}
+	return false
+}
+
+// sunerrorFunc call调用的sunerror包级函数名, 不是时返回空
+func sunerrorFunc(info *types.Info, call *ast.CallExpr) string {
+	fn, ok := typeutil.Callee(info, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != pkgPath {
+		return ""
+	}
+	if sig, ok := fn.Type().(*types.Signature); !ok || sig.Recv() != nil {
+		return ""
+	}
+	return fn.Name()
+}
+
+// constString expr为字符串常量时返回它的值
+func constString(info *types.Info, expr ast.Expr) (string, bool) {
+	tv, ok := info.Types[expr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(tv.Value), true
+}
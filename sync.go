@@ -0,0 +1,63 @@
+package sunerror
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var errSyncPanic = errors.New("sunerror: sync executor panic")
+
+// syncExecutor 同步执行器及其超时时间
+type syncExecutor struct {
+	fn      func(ctx context.Context, sunError *SunError) error
+	timeout time.Duration
+}
+
+// runSync 按注册顺序执行同步执行器, 失败原因合并到syncErr并打印日志
+func (e *SunError) runSync(ctx context.Context) {
+	var errs []error
+	for _, s := range e.syncFns {
+		if err := e.callSync(ctx, s); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return
+	}
+	e.syncErr = errors.Join(errs...)
+	e.logExecutorFailure(ctx, "sync executor failed, code=%s, errorID=%s: %v", e.code, e.GetErrorID(), e.syncErr)
+}
+
+// callSync 执行单个同步执行器, 超时后返回context.DeadlineExceeded, panic转换为error
+func (e *SunError) callSync(ctx context.Context, s syncExecutor) error {
+	if s.timeout <= 0 {
+		return e.callSyncOnce(ctx, s.fn)
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	// 超时后执行器可能仍在运行, 交给它一份快照, 避免与之后对e的写入产生竞争
+	snapshot := e.clone()
+	done := make(chan error, 1)
+	go func() {
+		done <- snapshot.callSyncOnce(ctx, s.fn)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *SunError) callSyncOnce(ctx context.Context, fn func(ctx context.Context, sunError *SunError) error) (err error) {
+	panicked := true
+	e.safeCall(ctx, func() {
+		err = fn(ctx, e)
+		panicked = false
+	})
+	if panicked {
+		return errSyncPanic
+	}
+	return err
+}
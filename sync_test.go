@@ -0,0 +1,20 @@
+package sunerror
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSyncExecutorFailureLogsThroughConfigEngine(t *testing.T) {
+	logs := captureConfigLog(t)
+	e := NewSunError(context.Background(), "SYNC_FAIL", "500", "sync", WithNoLogOption(), WithStackOption(false), WithLogEngine(nil),
+		WithSyncExecutor(func(ctx context.Context, e *SunError) error { return errors.New("audit write failed") }, 0))
+	if e.SyncExecutorErr() == nil {
+		t.Fatal("SyncExecutorErr() = nil")
+	}
+	if got := logs(); len(got) != 1 || !strings.Contains(got[0], "audit write failed") {
+		t.Fatalf("logs = %q", got)
+	}
+}
@@ -0,0 +1,106 @@
+package sunerror
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+)
+
+// TenantPolicy 单个租户的错误策略, 用于在不影响其他租户的情况下调整某个租户(如试点租户)已知错误的处理方式
+type TenantPolicy struct {
+	Levels        map[string]SunErrLevel // 按错误码覆盖日志等级, 如将已知的错误降为WarnLevel
+	Rate          float64                // 异步执行(执行器与全局钩子)的采样率(0~1], 0表示不额外采样, 在全局采样之后生效
+	CodeRates     map[string]float64     // 按错误码的采样率, 优先于Rate, 为0时该错误码不执行
+	AlertMinLevel SunErrLevel            // 触发全局钩子(告警)的最低等级, 与WithHookMinLevel同时生效, 零值为InfoLevel
+}
+
+// tenantExtractor 从ctx中获取租户ID的函数, 未设置时为nil
+var tenantExtractor atomic.Value
+
+var (
+	tenantMu       sync.RWMutex
+	tenantPolicies = make(map[string]*TenantPolicy)
+)
+
+// SetTenantExtractor 设置从ctx中获取租户ID的函数, 未通过WithTenantOption设置租户时构造错误时调用; 传nil时不再自动获取
+func SetTenantExtractor(fn func(ctx context.Context) string) {
+	tenantExtractor.Store(fn)
+}
+
+func tenantFromContext(ctx context.Context) string {
+	fn, _ := tenantExtractor.Load().(func(ctx context.Context) string)
+	if fn == nil || ctx == nil {
+		return ""
+	}
+	return fn(ctx)
+}
+
+// SetTenantPolicy 设置租户的错误策略, 对之后构造的错误生效
+func SetTenantPolicy(tenant string, p TenantPolicy) {
+	tenantMu.Lock()
+	defer tenantMu.Unlock()
+	tenantPolicies[tenant] = &p
+}
+
+// RemoveTenantPolicy 删除租户的错误策略, 该租户的错误恢复为默认处理
+func RemoveTenantPolicy(tenant string) {
+	tenantMu.Lock()
+	defer tenantMu.Unlock()
+	delete(tenantPolicies, tenant)
+}
+
+// GetTenantPolicy 返回租户的错误策略
+func GetTenantPolicy(tenant string) (TenantPolicy, bool) {
+	tenantMu.RLock()
+	defer tenantMu.RUnlock()
+	p, ok := tenantPolicies[tenant]
+	if !ok {
+		return TenantPolicy{}, false
+	}
+	return *p, true
+}
+
+// WithTenantOption 设置错误所属的租户, 不设置时使用SetTenantExtractor设置的函数从ctx中获取
+func WithTenantOption(tenant string) SunErrOption {
+	return func(e *SunError) {
+		e.tenant = tenant
+	}
+}
+
+// GetTenant 错误所属的租户, 未设置时为空
+func (e *SunError) GetTenant() string {
+	return e.tenant
+}
+
+// applyTenantPolicy 构造时确定租户并应用其策略中的日志等级
+func (e *SunError) applyTenantPolicy(ctx context.Context) {
+	if len(e.tenant) == 0 {
+		e.tenant = tenantFromContext(ctx)
+		if len(e.tenant) == 0 {
+			return
+		}
+	}
+	tenantMu.RLock()
+	p := tenantPolicies[e.tenant]
+	tenantMu.RUnlock()
+	if p == nil {
+		return
+	}
+	e.tenantPolicy = p
+	if level, ok := p.Levels[e.code]; ok {
+		e.level = level
+	}
+}
+
+// sample 按租户策略判断本次是否执行异步执行器
+func (p *TenantPolicy) sample(code string) bool {
+	rate, ok := p.CodeRates[code]
+	if ok && rate <= 0 {
+		return false
+	}
+	if !ok {
+		rate = p.Rate
+	}
+	return rate <= 0 || rate >= 1 || rand.Float64() < rate
+}
@@ -0,0 +1,105 @@
+package sunerror
+
+import (
+	"context"
+	"net/url"
+	"strings"
+)
+
+// Translator 在服务边界对错误做转换(映射对外错误码/去除内部信息/补充用户提示等)
+// 实现应返回新的SunError而不是修改入参, 入参的原始错误可能仍在被记录或上报
+type Translator interface {
+	Translate(ctx context.Context, e *SunError) *SunError
+}
+
+// TranslatorFunc 函数形式的Translator
+type TranslatorFunc func(ctx context.Context, e *SunError) *SunError
+
+func (f TranslatorFunc) Translate(ctx context.Context, e *SunError) *SunError {
+	return f(ctx, e)
+}
+
+type chain []Translator
+
+func (c chain) Translate(ctx context.Context, e *SunError) *SunError {
+	for _, t := range c {
+		if e == nil {
+			return nil
+		}
+		e = t.Translate(ctx, e)
+	}
+	return e
+}
+
+// ChainTranslators 按顺序组合多个Translator, 前一个的输出作为下一个的输入
+func ChainTranslators(translators ...Translator) Translator {
+	var c chain
+	for _, t := range translators {
+		if nested, ok := t.(chain); ok {
+			c = append(c, nested...)
+		} else if t != nil {
+			c = append(c, t)
+		}
+	}
+	return c
+}
+
+// MapCodeTranslator 将内部错误码映射为对外错误码, 未在mapping中的错误码映射为defaultCode(为空时保持不变)
+func MapCodeTranslator(mapping map[string]string, defaultCode string) Translator {
+	return TranslatorFunc(func(ctx context.Context, e *SunError) *SunError {
+		out := e.clone()
+		if code, ok := mapping[e.code]; ok {
+			out.code = code
+		} else if len(defaultCode) > 0 {
+			out.code = defaultCode
+		}
+		return out
+	})
+}
+
+// StripInternalsTranslator 去除detail/函数名/堆栈/下游错误信息, 只保留三元组与errorID等对外字段
+func StripInternalsTranslator() Translator {
+	return TranslatorFunc(func(ctx context.Context, e *SunError) *SunError {
+		out := e.clone()
+		out.stripInternals()
+		return out
+	})
+}
+
+// UserMsgTranslator 按错误码补充面向终端用户的提示, lookup返回false时保持不变
+// lookup可以从ctx中读取语言等信息
+func UserMsgTranslator(lookup func(ctx context.Context, code string) (string, bool)) Translator {
+	return TranslatorFunc(func(ctx context.Context, e *SunError) *SunError {
+		userMsg, ok := lookup(ctx, e.code)
+		if !ok {
+			return e
+		}
+		out := e.clone()
+		out.userMsg = userMsg
+		return out
+	})
+}
+
+// DocsLinkTranslator 以baseURL拼接错误码作为错误文档链接
+func DocsLinkTranslator(baseURL string) Translator {
+	return TranslatorFunc(func(ctx context.Context, e *SunError) *SunError {
+		out := e.clone()
+		out.docsURL = strings.TrimRight(baseURL, "/") + "/" + url.PathEscape(e.code)
+		return out
+	})
+}
+
+// clone 返回浅拷贝, stack切片构造后只读, 可以共享
+// 副本不属于对象池, Release副本不会回收原错误; 副本使用新的Error()缓存, 派生出的错误会重新格式化
+// 对象池中的错误的stack指向池中复用的缓冲区, 原错误归还后会被覆盖, 副本需要单独复制一份
+func (e *SunError) clone() *SunError {
+	out := newSunError()
+	cache := out.errCache
+	*out = *e
+	if e.pooled != nil && len(e.stack) > 0 {
+		out.stack = append([]byte(nil), e.stack...)
+	}
+	out.pooled = nil
+	out.errCache = cache
+	return out
+}
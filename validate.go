@@ -0,0 +1,84 @@
+package sunerror
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+)
+
+// Misuse 严格模式下构造错误时发现的误用, 如错误码为空或不合法、跳过的栈深度为负数、需要打印日志但没有日志引擎
+type Misuse struct {
+	Code     string   // 错误码
+	FnName   string   // 构造错误的调用点
+	Problems []string // 发现的问题
+}
+
+func (m *Misuse) Error() string {
+	return "sunerror: misuse at " + m.FnName + " (code=" + m.Code + "): " + strings.Join(m.Problems, "; ")
+}
+
+// MisuseHandler 误用的处理函数
+type MisuseHandler func(ctx context.Context, m *Misuse)
+
+var (
+	strictMode    atomic.Bool
+	misuseHandler atomic.Value
+)
+
+// SetStrictMode 开启严格模式后, 每次构造错误都会检查选项组合, 发现误用时交给SetMisuseHandler设置的函数处理
+// 默认关闭: 不合理的选项被静默接受(如没有日志引擎时不打印日志), 测试与开发环境建议开启
+func SetStrictMode(strict bool) {
+	strictMode.Store(strict)
+}
+
+// StrictMode 是否开启了严格模式
+func StrictMode() bool {
+	return strictMode.Load()
+}
+
+// SetMisuseHandler 设置误用的处理函数, 传nil时恢复默认处理(以*Misuse panic, 让测试直接失败)
+func SetMisuseHandler(h MisuseHandler) {
+	misuseHandler.Store(h)
+}
+
+// WithRestoredOption 标记错误是从外部数据(下游响应、消息、序列化结果)还原的, 错误码由对方决定, 严格模式不校验错误码
+// 如go-zero的数字错误码"-1"不符合Code.Validate, 还原时不应被当作误用; 自带的From*还原函数都会设置
+func WithRestoredOption() SunErrOption {
+	return func(e *SunError) {
+		e.restored = true
+	}
+}
+
+// validate 检查选项应用后的错误, 在捕获调用点之后、打印日志之前调用
+func (e *SunError) validate(ctx context.Context, base int) {
+	var problems []string
+	// 还原的错误码由对方决定, 不检查
+	if !e.restored {
+		if len(e.code) == 0 {
+			problems = append(problems, "empty code")
+		} else if err := Code(e.code).Validate(); err != nil {
+			problems = append(problems, strings.TrimPrefix(err.Error(), "sunerror: "))
+		}
+	}
+	if e.depth < base {
+		problems = append(problems, "negative skip depth")
+	}
+	if _, ok := levelNames[e.level]; !ok {
+		problems = append(problems, "unknown level "+e.level.String())
+	}
+	if !e.noLog && e.getLogFunc() == nil {
+		problems = append(problems, "no log engine")
+	}
+	if e.storeStack && e.stackRows <= 0 {
+		problems = append(problems, "non-positive stack rows")
+	}
+	if len(problems) == 0 {
+		return
+	}
+	m := &Misuse{Code: e.code, FnName: e.GetFnName(), Problems: problems}
+	if h, _ := misuseHandler.Load().(MisuseHandler); h != nil {
+		h(ctx, m)
+		return
+	}
+	panic(m)
+}
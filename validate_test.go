@@ -0,0 +1,28 @@
+package sunerror
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStrictModeSkipsRestoredCode(t *testing.T) {
+	SetStrictMode(true)
+	var misuses []*Misuse
+	SetMisuseHandler(func(ctx context.Context, m *Misuse) { misuses = append(misuses, m) })
+	t.Cleanup(func() {
+		SetStrictMode(false)
+		SetMisuseHandler(nil)
+	})
+	ctx := context.Background()
+
+	FromProblemDetails(ctx, ProblemDetails{Code: "-1", Title: "go-zero failure"})
+	NewSunError(ctx, "", "500", "restored", WithNoLogOption(), WithRestoredOption())
+	if len(misuses) != 0 {
+		t.Fatalf("restored errors reported as misuse: %v", misuses[0])
+	}
+
+	NewSunError(ctx, "-1", "500", "local", WithNoLogOption())
+	if len(misuses) != 1 {
+		t.Fatalf("invalid local code not reported, misuses = %d", len(misuses))
+	}
+}
@@ -0,0 +1,80 @@
+package sunerror
+
+import (
+	"context"
+	"strings"
+)
+
+// NewValidationError使用的错误码、status与msg, 可通过opts覆盖
+const (
+	ValidationCode   Code = "VALIDATION_FAILED"
+	ValidationStatus      = "FAILED"
+	ValidationMsg         = "validation failed"
+)
+
+// FieldViolation 单个字段的校验失败
+type FieldViolation struct {
+	Field   string `json:"field"`          // 字段路径, 如items[0].sku
+	Rule    string `json:"rule,omitempty"` // 未通过的规则, 如required/max
+	Message string `json:"message"`        // 面向调用方的说明
+}
+
+func (v FieldViolation) String() string {
+	if len(v.Rule) == 0 {
+		return v.Field + ": " + v.Message
+	}
+	return v.Field + ": " + v.Message + " (" + v.Rule + ")"
+}
+
+// violationList 延迟格式化为detail, 只在打印日志/Error()时拼接
+type violationList []FieldViolation
+
+func (l violationList) String() string {
+	parts := make([]string, len(l))
+	for i, v := range l {
+		parts[i] = v.String()
+	}
+	return strings.Join(parts, "; ")
+}
+
+// NewValidationError 构造参数校验失败的错误, kind为ValidationKind, 以WarnLevel打印且不保存堆栈
+// detail为所有字段的校验结果, 如"name: must not be empty (required); age: must be positive (min)"
+// 字段明细会渲染到problem+json的invalid-params、gRPC的BadRequest与ResponseBody的violations中
+func NewValidationError(ctx context.Context, violations []FieldViolation, opts ...SunErrOption) *SunError {
+	fields := []SunErrOption{
+		WithSkipDepthOption(1),
+		WithKindOption(ValidationKind),
+		WithLogLevelOption(WarnLevel),
+		WithStackOption(false),
+		WithViolationsOption(violations...),
+	}
+	return NewSunError(ctx, ValidationCode, ValidationStatus, ValidationMsg, append(fields, opts...)...)
+}
+
+// WithViolationsOption 设置字段校验失败明细, 同时以明细作为detail; 之后的WithDetailOption会覆盖detail但保留明细
+func WithViolationsOption(violations ...FieldViolation) SunErrOption {
+	return func(e *SunError) {
+		e.violations = append([]FieldViolation(nil), violations...)
+		if len(violations) > 0 {
+			e.detail = ""
+			e.lazyDetail = &lazyString{format: "%s", args: []interface{}{violationList(e.violations)}}
+		}
+	}
+}
+
+// GetViolations 字段校验失败明细, 返回的切片只读
+func (e *SunError) GetViolations() []FieldViolation {
+	return e.violations
+}
+
+func equalViolations(a, b []FieldViolation) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
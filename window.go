@@ -0,0 +1,199 @@
+package sunerror
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WindowConfig ErrorGroupByWindow的配置, 零值字段使用默认值
+type WindowConfig struct {
+	ByFingerprint bool // 按指纹(错误码+调用点)分组, 默认只按错误码分组
+	Samples       int  // 每组保留的detail样本数, 默认3
+	MaxGroups     int  // 最多的分组数, 超出后新的分组只计入总数, 默认100
+}
+
+// WindowGroup 一个分组在窗口内的统计
+type WindowGroup struct {
+	Key     string      // 分组键, 错误码或指纹
+	Code    string      // 错误码
+	Count   int         // 错误数
+	First   time.Time   // 第一次出现的时间
+	Last    time.Time   // 最后一次出现的时间
+	Level   SunErrLevel // 组内最高的等级
+	Samples []string    // 前Samples个错误的detail, 为空的detail不保留
+}
+
+type windowGroup struct {
+	WindowGroup
+	first *SunError // 组内第一个错误, 作为汇总错误的原始错误
+}
+
+// ErrorGroupByWindow 在一个窗口(通常是一次批处理任务的运行)内按错误码或指纹汇总大量行级错误, 窗口结束时产生一个汇总错误向上报告
+// 只保留每组的计数、首末时间与少量样本, 内存占用与错误数无关; 行级错误通常以WithNoLogOption构造, 避免逐条打印
+// 可以在多个协程中并发Add
+//
+//	w := sunerror.NewErrorGroupByWindow(sunerror.WindowConfig{})
+//	for _, row := range rows {
+//		if err := process(row); err != nil {
+//			w.Add(sunerror.FromError(ctx, err, sunerror.WithNoLogOption()))
+//		}
+//	}
+//	if err := w.Flush(ctx, "IMPORT_1001", "FAILED", "import finished with errors"); err != nil { ... }
+type ErrorGroupByWindow struct {
+	mu      sync.Mutex
+	cfg     WindowConfig
+	start   time.Time
+	total   int
+	dropped int
+	groups  map[string]*windowGroup
+}
+
+// NewErrorGroupByWindow 创建汇总器, 窗口从此刻开始
+func NewErrorGroupByWindow(cfg WindowConfig) *ErrorGroupByWindow {
+	if cfg.Samples <= 0 {
+		cfg.Samples = 3
+	}
+	if cfg.MaxGroups <= 0 {
+		cfg.MaxGroups = 100
+	}
+	return &ErrorGroupByWindow{cfg: cfg, start: time.Now(), groups: make(map[string]*windowGroup)}
+}
+
+// Add 计入一个错误, nil会被忽略
+func (w *ErrorGroupByWindow) Add(e *SunError) {
+	if e == nil {
+		return
+	}
+	key := e.code
+	if w.cfg.ByFingerprint {
+		key = e.Fingerprint()
+	}
+	now := time.Now()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.total++
+	g := w.groups[key]
+	if g == nil {
+		if len(w.groups) >= w.cfg.MaxGroups {
+			w.dropped++
+			return
+		}
+		g = &windowGroup{WindowGroup: WindowGroup{Key: key, Code: e.code, First: now, Level: e.level}, first: e}
+		w.groups[key] = g
+	}
+	g.Count++
+	g.Last = now
+	if e.level > g.Level {
+		g.Level = e.level
+	}
+	if len(g.Samples) < w.cfg.Samples {
+		if detail := e.GetDetail(); len(detail) > 0 {
+			g.Samples = append(g.Samples, detail)
+		}
+	}
+}
+
+// Len 窗口内计入的错误总数
+func (w *ErrorGroupByWindow) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.total
+}
+
+// Groups 按错误数从多到少返回各分组的统计
+func (w *ErrorGroupByWindow) Groups() []WindowGroup {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.sortedGroups()
+}
+
+// Summary 将窗口内的错误汇总为一个SunError, 没有错误时返回nil
+// detail为总数、窗口时长与各分组的计数、首末时间及样本; 等级取最高的等级, 所有错误分类相同时沿用该分类
+// 原始错误为各分组的第一个错误(errors.Join), errors.Is/As可以匹配到其中任意一个
+func (w *ErrorGroupByWindow) Summary(ctx context.Context, code Code, status, msg string, opts ...SunErrOption) *SunError {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.summary(ctx, code, status, msg, opts)
+}
+
+// Flush 与Summary相同, 之后重置计数并开始新的窗口
+func (w *ErrorGroupByWindow) Flush(ctx context.Context, code Code, status, msg string, opts ...SunErrOption) *SunError {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	e := w.summary(ctx, code, status, msg, opts)
+	w.start, w.total, w.dropped = time.Now(), 0, 0
+	w.groups = make(map[string]*windowGroup)
+	return e
+}
+
+func (w *ErrorGroupByWindow) summary(ctx context.Context, code Code, status, msg string, opts []SunErrOption) *SunError {
+	if w.total == 0 {
+		return nil
+	}
+	groups := w.sortedGroups()
+	firsts := make([]error, 0, len(groups))
+	level := UnknownLevel
+	kind := w.groups[groups[0].Key].first.kind
+	var b strings.Builder
+	b.WriteString(strconv.Itoa(w.total))
+	b.WriteString(" errors in ")
+	b.WriteString(time.Since(w.start).Round(time.Millisecond).String())
+	for _, g := range groups {
+		first := w.groups[g.Key].first
+		firsts = append(firsts, first)
+		if g.Level > level {
+			level = g.Level
+		}
+		if first.kind != kind {
+			kind = UnknownKind
+		}
+		b.WriteString("; ")
+		b.WriteString(g.Key)
+		b.WriteString(" x")
+		b.WriteString(strconv.Itoa(g.Count))
+		b.WriteString(" first=")
+		b.WriteString(g.First.Format(time.RFC3339))
+		b.WriteString(" last=")
+		b.WriteString(g.Last.Format(time.RFC3339))
+		if len(g.Samples) > 0 {
+			b.WriteString(" samples=[")
+			b.WriteString(strings.Join(g.Samples, " | "))
+			b.WriteByte(']')
+		}
+	}
+	if w.dropped > 0 {
+		b.WriteString("; ")
+		b.WriteString(strconv.Itoa(w.dropped))
+		b.WriteString(" errors in other groups")
+	}
+	fields := []SunErrOption{
+		WithDetailOption("%s", b.String()),
+		WithLogLevelOption(level),
+		WithKindOption(kind),
+		WithCauseOption(errors.Join(firsts...)),
+		WithSkipDepthOption(2),
+	}
+	return NewSunError(ctx, code, status, msg, append(fields, opts...)...)
+}
+
+func (w *ErrorGroupByWindow) sortedGroups() []WindowGroup {
+	groups := make([]WindowGroup, 0, len(w.groups))
+	for _, g := range w.groups {
+		wg := g.WindowGroup
+		wg.Samples = append([]string(nil), g.Samples...)
+		groups = append(groups, wg)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		return groups[i].Key < groups[j].Key
+	})
+	return groups
+}